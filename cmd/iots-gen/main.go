@@ -0,0 +1,174 @@
+// Command iots-gen generates io-ts codecs for every type marked with //iots:export in the
+// packages matching its arguments:
+//
+//	iots-gen -o web/src/codecs.ts ./...
+//
+// The generator works on Go types rather than source, so iots-gen writes a temporary program
+// importing the marked types into the current directory and runs it. Run it from a module that
+// requires github.com/VictorMarcolino/golang-struct-to-io-ts.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/generators"
+)
+
+func main() {
+	output := flag.String("o", "", "write the generated module to this file instead of stdout")
+	flag.Parse()
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	if err := run(patterns, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "iots-gen:", err)
+		os.Exit(1)
+	}
+}
+
+// run discovers the marked types and writes the module generated for them
+func run(patterns []string, output string) error {
+	exported, err := generators.ExportedTypes(patterns...)
+	if err != nil {
+		return err
+	}
+	roots, skipped := selectRoots(exported)
+	for _, warning := range skipped {
+		fmt.Fprintln(os.Stderr, "iots-gen:", warning)
+	}
+	if len(roots) == 0 {
+		return fmt.Errorf("no types marked with //iots:export in %v", patterns)
+	}
+
+	result, err := generate(roots)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(result)
+		return err
+	}
+	return os.WriteFile(output, result, 0o644)
+}
+
+// selectRoots returns the marked types the generated program can import, and a warning for each
+// type it skips
+func selectRoots(exported []generators.ExportedType) ([]generators.ExportedType, []string) {
+	var roots []generators.ExportedType
+	var skipped []string
+	for _, e := range exported {
+		switch {
+		case e.Generic:
+			skipped = append(skipped, fmt.Sprintf("%s: skipping generic type %s", e.Position, e.Name))
+		case !isExported(e.Name):
+			skipped = append(skipped, fmt.Sprintf("%s: skipping unexported type %s", e.Position, e.Name))
+		case e.PkgName == "main":
+			skipped = append(skipped, fmt.Sprintf("%s: skipping type %s of package main, which cannot be imported", e.Position, e.Name))
+		default:
+			roots = append(roots, e)
+		}
+	}
+	return roots, skipped
+}
+
+// generate writes a program that generates the roots, runs it and returns its output
+func generate(roots []generators.ExportedType) ([]byte, error) {
+	dir, err := os.MkdirTemp(".", "iots-gen-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var source bytes.Buffer
+	if err := program.Execute(&source, newProgramData(roots)); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), source.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir))
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running generator: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// programData lists the imports and roots of the generated program
+type programData struct {
+	Imports []programImport
+	Roots   []string
+}
+
+// programImport is a package imported by the generated program under an alias
+type programImport struct {
+	Alias string
+	Path  string
+}
+
+// newProgramData aliases each package as p0, p1... and refers to each root through its alias
+func newProgramData(roots []generators.ExportedType) programData {
+	var data programData
+	aliases := make(map[string]string)
+	for _, root := range roots {
+		alias, ok := aliases[root.PkgPath]
+		if !ok {
+			alias = fmt.Sprintf("p%d", len(aliases))
+			aliases[root.PkgPath] = alias
+			data.Imports = append(data.Imports, programImport{Alias: alias, Path: root.PkgPath})
+		}
+		data.Roots = append(data.Roots, alias+"."+root.Name)
+	}
+	return data
+}
+
+// isExported reports whether a type name can be referenced from another package
+func isExported(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+var program = template.Must(template.New("program").Parse(`// Code generated by iots-gen. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/generators"
+{{- range .Imports}}
+	{{.Alias}} {{printf "%q" .Path}}
+{{- end}}
+)
+
+func main() {
+	result, diagnostics, err := generators.NewIoTsGenerator().GenerateAllWithDiagnostics(
+{{- range .Roots}}
+		*new({{.}}),
+{{- end}}
+	)
+	for _, d := range diagnostics {
+		if d.Severity == generators.SeverityWarning {
+			fmt.Fprintln(os.Stderr, d.Error())
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Print(result)
+}
+`))
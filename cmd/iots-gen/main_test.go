@@ -0,0 +1,44 @@
+package main
+
+import (
+	"go/token"
+
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/generators"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("iots-gen", func() {
+	It("should alias each package once and refer to the roots through their alias", func() {
+		data := newProgramData([]generators.ExportedType{
+			{PkgPath: "example.com/api", PkgName: "api", Name: "Order"},
+			{PkgPath: "example.com/billing", PkgName: "billing", Name: "Invoice"},
+			{PkgPath: "example.com/api", PkgName: "api", Name: "Customer"},
+		})
+
+		Expect(data.Imports).To(Equal([]programImport{
+			{Alias: "p0", Path: "example.com/api"},
+			{Alias: "p1", Path: "example.com/billing"},
+		}))
+		Expect(data.Roots).To(Equal([]string{"p0.Order", "p1.Invoice", "p0.Customer"}))
+	})
+
+	It("should skip the types the generated program cannot import", func() {
+		position := token.Position{Filename: "types.go", Line: 3, Column: 6}
+		roots, skipped := selectRoots([]generators.ExportedType{
+			{PkgPath: "example.com/api", PkgName: "api", Name: "Order", Position: position},
+			{PkgPath: "example.com/api", PkgName: "api", Name: "Page", Position: position, Generic: true},
+			{PkgPath: "example.com/api", PkgName: "api", Name: "draft", Position: position},
+			{PkgPath: "example.com/cmd/server", PkgName: "main", Name: "Config", Position: position},
+		})
+
+		Expect(roots).To(Equal([]generators.ExportedType{
+			{PkgPath: "example.com/api", PkgName: "api", Name: "Order", Position: position},
+		}))
+		Expect(skipped).To(Equal([]string{
+			"types.go:3:6: skipping generic type Page",
+			"types.go:3:6: skipping unexported type draft",
+			"types.go:3:6: skipping type Config of package main, which cannot be imported",
+		}))
+	})
+})
@@ -0,0 +1,26 @@
+package fixtures
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+}
+
+type OrderItem struct {
+	Price   float64        `json:"price"`
+	OnPrice chan float64   `json:"onPrice"`
+	Amount  complex128     `json:"amount"`
+	Notify  func(int) bool `json:"notify"`
+}
+
+type Order struct {
+	Items []OrderItem `json:"items"`
+}
+
+type Invoice struct {
+	ID       int64    `json:"id,string"`
+	Paid     bool     `json:"paid,string"`
+	Total    *float64 `json:"total,string,omitempty"`
+	Number   string   `json:"number,string"`
+	Lines    []int    `json:"lines,string"`
+	Severity Severity `json:"severity,string"`
+	Currency string   `json:",omitzero"`
+}
@@ -0,0 +1,34 @@
+package fixtures
+
+//iots:export
+type Shipment struct {
+	ID string `json:"id"`
+	// Carrier may be missing for pickups
+	//iots:optional
+	Carrier string `json:"carrier"`
+	Secret  string `json:"secret"` //iots:ignore
+	Parcel
+	Labels Labels `json:"labels"`
+}
+
+type Parcel struct {
+	Weight int    `json:"weight"`
+	Note   string `json:"note"` //iots:optional
+}
+
+// Labels are printed on the shipment
+//
+//iots:export
+type Labels []string
+
+type (
+	//iots:export
+	Tracking struct {
+		Code string `json:"code" iots:"type=NonEmptyString,import=io-ts-types"` //iots:optional
+	}
+
+	//iots:export
+	Box[T any] struct {
+		Item T `json:"item"`
+	}
+)
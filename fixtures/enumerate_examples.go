@@ -15,6 +15,13 @@ const (
 	CodeTwo ExampleInt = 2
 )
 
+type Big uint64
+
+const (
+	BigMin Big = 0
+	BigMax Big = 18446744073709551615
+)
+
 type Example struct {
 	ExampleString      ExampleString   `json:"exampleString"`
 	ExampleInt         ExampleInt      `json:"exampleInt"`
@@ -33,3 +40,62 @@ type RecursionExample struct {
 type AtExample struct {
 	AtExample string `json:"@atExample"`
 }
+
+type Severity int
+
+const (
+	Low Severity = iota
+	Medium
+	High
+)
+
+type Alert struct {
+	Severity Severity `json:"severity"`
+}
+
+type Permission int
+
+const (
+	PermissionRead Permission = 1 << iota
+	PermissionWrite
+	PermissionExec
+	PermissionAll = PermissionRead | PermissionWrite | PermissionExec
+)
+
+type FileMode struct {
+	Permission Permission `json:"permission"`
+}
+
+type AccountStatus string
+
+const (
+	// Active account
+	AccountActive    AccountStatus = "active"
+	AccountSuspended AccountStatus = "suspended" // Suspended by an administrator
+	AccountClosed    AccountStatus = "closed"
+)
+
+func (s AccountStatus) String() string {
+	switch s {
+	case AccountActive:
+		return "Active"
+	case AccountSuspended:
+		return "Suspended"
+	}
+	return "Closed"
+}
+
+type Account struct {
+	Status AccountStatus `json:"status"`
+}
+
+type Toggle bool
+
+const (
+	ToggleOn  Toggle = true
+	ToggleOff Toggle = false
+)
+
+type Switch struct {
+	State Toggle `json:"state"`
+}
@@ -0,0 +1,62 @@
+package fixtures
+
+type Color int
+
+const (
+	ColorRed Color = iota
+	ColorGreen
+	ColorBlue
+)
+
+func (c Color) MarshalText() ([]byte, error) {
+	switch c {
+	case ColorRed:
+		return []byte("red"), nil
+	case ColorGreen:
+		return []byte("green"), nil
+	}
+	return []byte("blue"), nil
+}
+
+type Shade int
+
+const (
+	ShadeLight Shade = 1
+	ShadeDark  Shade = 2
+)
+
+func (s *Shade) MarshalJSON() ([]byte, error) {
+	if *s == ShadeDark {
+		return []byte("20"), nil
+	}
+	return []byte("10"), nil
+}
+
+type Mood int
+
+const (
+	MoodHappy Mood = iota
+	MoodUnknown
+)
+
+type moodError struct{}
+
+func (moodError) Error() string {
+	return "mood cannot be serialized"
+}
+
+func (m Mood) MarshalJSON() ([]byte, error) {
+	if m == MoodUnknown {
+		return nil, moodError{}
+	}
+	return []byte(`"happy"`), nil
+}
+
+type Palette struct {
+	Color Color `json:"color"`
+	Shade Shade `json:"shade"`
+}
+
+type Diary struct {
+	Mood Mood `json:"mood"`
+}
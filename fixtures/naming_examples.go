@@ -0,0 +1,31 @@
+package fixtures
+
+type Access string
+
+const (
+	LevelRead  Access = "read"
+	LevelWrite Access = "write"
+)
+
+type AccessLevel string
+
+const (
+	Read  AccessLevel = "r"
+	Write AccessLevel = "w"
+)
+
+type Grant struct {
+	Access      Access      `json:"access"`
+	AccessLevel AccessLevel `json:"accessLevel"`
+}
+
+type Tier string
+
+const (
+	TierGold Tier = "gold"
+	Gold     Tier = "premium"
+)
+
+type Membership struct {
+	Tier Tier `json:"tier"`
+}
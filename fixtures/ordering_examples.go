@@ -0,0 +1,11 @@
+package fixtures
+
+type Author struct {
+	Name  string  `json:"name"`
+	Books []*Book `json:"books"`
+}
+
+type Book struct {
+	Title  string  `json:"title"`
+	Author *Author `json:"author"`
+}
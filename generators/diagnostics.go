@@ -0,0 +1,169 @@
+package generators
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Severity classifies a Diagnostic
+type Severity int
+
+const (
+	// SeverityWarning marks a problem that still produced usable output
+	SeverityWarning Severity = iota
+	// SeverityError marks a problem that makes Generate fail
+	SeverityError
+)
+
+// String returns the lowercase name of the severity
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Diagnostic describes a problem found while generating io-ts types
+type Diagnostic struct {
+	Severity Severity
+	// TypePath is the Go type the problem refers to, e.g. "github.com/acme/api.Price"
+	TypePath string
+	// FieldPath locates the field from the root type, e.g. "Order.Items[].Price"
+	FieldPath string
+	Message   string
+	Err       error
+}
+
+// Error formats the diagnostic as "severity: field path (type path): message"
+func (d Diagnostic) Error() string {
+	var sb strings.Builder
+	sb.WriteString(d.Severity.String())
+	sb.WriteString(": ")
+	if d.FieldPath != "" {
+		sb.WriteString(d.FieldPath)
+		sb.WriteString(" ")
+	}
+	if d.TypePath != "" {
+		sb.WriteString("(")
+		sb.WriteString(d.TypePath)
+		sb.WriteString(") ")
+	}
+	sb.WriteString(d.Message)
+	if d.Err != nil {
+		sb.WriteString(": ")
+		sb.WriteString(d.Err.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns the underlying error, if any
+func (d Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+// GenerateError is returned by Generate when at least one error diagnostic was reported.
+// Diagnostics holds every diagnostic of the run, warnings included.
+type GenerateError struct {
+	Diagnostics []Diagnostic
+}
+
+// Error lists every error diagnostic, one per line
+func (e *GenerateError) Error() string {
+	errs := e.Errors()
+	lines := make([]string, 0, len(errs))
+	for _, d := range errs {
+		lines = append(lines, d.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Errors returns only the diagnostics with SeverityError
+func (e *GenerateError) Errors() []Diagnostic {
+	var errs []Diagnostic
+	for _, d := range e.Diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	return errs
+}
+
+// diagnostics collects the diagnostics of a generation run together with the field path being walked
+type diagnostics struct {
+	items []Diagnostic
+	path  []string
+	once  map[string]struct{}
+}
+
+// pushPath enters a field path segment; "[]" segments are appended without a separator
+func (d *diagnostics) pushPath(segment string) {
+	d.path = append(d.path, segment)
+}
+
+// popPath leaves the last field path segment
+func (d *diagnostics) popPath() {
+	d.path = d.path[:len(d.path)-1]
+}
+
+// fieldPath renders the current field path, e.g. "Order.Items[].Price"
+func (d *diagnostics) fieldPath() string {
+	var sb strings.Builder
+	for i, segment := range d.path {
+		if i > 0 && !strings.HasPrefix(segment, "[") && !strings.HasPrefix(segment, "{") {
+			sb.WriteString(".")
+		}
+		sb.WriteString(segment)
+	}
+	return sb.String()
+}
+
+// report records a diagnostic at the current field path, ignoring exact duplicates
+func (d *diagnostics) report(severity Severity, t reflect.Type, err error, format string, args ...interface{}) {
+	diagnostic := Diagnostic{
+		Severity:  severity,
+		TypePath:  typePath(t),
+		FieldPath: d.fieldPath(),
+		Message:   fmt.Sprintf(format, args...),
+		Err:       err,
+	}
+	for _, existing := range d.items {
+		if existing.Severity == diagnostic.Severity && existing.FieldPath == diagnostic.FieldPath && existing.Message == diagnostic.Message {
+			return
+		}
+	}
+	d.items = append(d.items, diagnostic)
+}
+
+// reportOnce records a diagnostic only the first time the given key is seen
+func (d *diagnostics) reportOnce(key string, severity Severity, t reflect.Type, err error, format string, args ...interface{}) {
+	if _, seen := d.once[key]; seen {
+		return
+	}
+	if d.once == nil {
+		d.once = make(map[string]struct{})
+	}
+	d.once[key] = struct{}{}
+	d.report(severity, t, err, format, args...)
+}
+
+// hasErrors reports whether any error diagnostic was recorded
+func (d *diagnostics) hasErrors() bool {
+	for _, item := range d.items {
+		if item.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// typePath returns the fully qualified Go name of a type, or its string form for unnamed types
+func typePath(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
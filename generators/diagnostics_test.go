@@ -0,0 +1,123 @@
+package generators_test
+
+import (
+	"errors"
+
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/fixtures"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/generators"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// unloadedPackage matches the warning reported for types of this test package, whose source cannot be loaded
+var unloadedPackage = HaveField("Message", "enum detection and source directives skipped")
+
+var _ = Describe("IO-TS:Diagnostics", func() {
+	It("should report unsupported kinds with their field path in strict mode", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{Strict: true})
+		result, err := generator.Generate(fixtures.Order{})
+
+		Expect(result).To(BeEmpty())
+		var generateErr *generators.GenerateError
+		Expect(errors.As(err, &generateErr)).To(BeTrue())
+
+		paths := map[string]string{}
+		for _, d := range generateErr.Errors() {
+			paths[d.FieldPath] = d.TypePath
+		}
+		Expect(paths).To(Equal(map[string]string{
+			"Order.Items[].OnPrice": "chan float64",
+			"Order.Items[].Amount":  "complex128",
+			"Order.Items[].Notify":  "func(int) bool",
+		}))
+		Expect(err.Error()).To(ContainSubstring("error: Order.Items[].OnPrice (chan float64) unsupported kind chan"))
+	})
+
+	It("should emit unsupported kinds as t.unknown with a warning and their field path", func() {
+		result, diagnostics, err := generators.NewIoTsGenerator().GenerateWithDiagnostics(fixtures.Order{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("onPrice: t.unknown,"))
+		Expect(result).To(ContainSubstring("notify: t.unknown,"))
+		paths := map[string]string{}
+		for _, d := range diagnostics {
+			Expect(d.Severity).To(Equal(generators.SeverityWarning))
+			Expect(d.Message).To(HavePrefix("unsupported kind"))
+			paths[d.FieldPath] = d.TypePath
+		}
+		Expect(paths).To(Equal(map[string]string{
+			"Order.Items[].OnPrice": "chan float64",
+			"Order.Items[].Amount":  "complex128",
+			"Order.Items[].Notify":  "func(int) bool",
+		}))
+		Expect(diagnostics[0].Error()).To(Equal("warning: Order.Items[].OnPrice (chan float64) unsupported kind chan"))
+	})
+
+	It("should return the warnings of GenerateAll and GenerateNamed", func() {
+		generator := generators.NewIoTsGenerator()
+		_, diagnostics, err := generator.GenerateAllWithDiagnostics(fixtures.Order{}, fixtures.Order{})
+		Expect(err).To(BeNil())
+		Expect(diagnostics).To(HaveLen(3))
+
+		_, diagnostics, err = generator.GenerateNamedWithDiagnostics("Purchase", fixtures.Order{})
+		Expect(err).To(BeNil())
+		Expect(diagnostics).To(HaveLen(3))
+		Expect(diagnostics[0].FieldPath).To(HavePrefix("Purchase."))
+	})
+
+	It("should reject type names that are not valid TypeScript identifiers", func() {
+		type Holder struct {
+			Page fixtures.Page[int] `json:"page"`
+		}
+		generator := generators.NewIoTsGenerator()
+		_, err := generator.Generate(Holder{})
+
+		var generateErr *generators.GenerateError
+		Expect(errors.As(err, &generateErr)).To(BeTrue())
+		Expect(generateErr.Errors()).To(HaveLen(1))
+		Expect(generateErr.Errors()[0].FieldPath).To(Equal("Holder.Page"))
+		Expect(generateErr.Errors()[0].Message).To(ContainSubstring("is not a valid TypeScript identifier"))
+	})
+
+	It("should return every diagnostic along with the error", func() {
+		type Holder struct {
+			Page   fixtures.Page[int] `json:"page"`
+			Notify func()             `json:"notify"`
+		}
+		result, diagnostics, err := generators.NewIoTsGenerator().GenerateWithDiagnostics(Holder{})
+
+		Expect(result).To(BeEmpty())
+		Expect(err).NotTo(BeNil())
+		Expect(diagnostics).To(ConsistOf(
+			unloadedPackage,
+			HaveField("FieldPath", "Holder.Page"),
+			HaveField("FieldPath", "Holder.Notify"),
+		))
+	})
+
+	It("should return an error for inputs that are not structs", func() {
+		generator := generators.NewIoTsGenerator()
+		_, err := generator.Generate(42)
+		Expect(err).To(MatchError("error: (int) input is not a struct"))
+
+		_, err = generator.Generate(nil)
+		Expect(err).To(MatchError("error: input is nil"))
+	})
+
+	It("should report packages that cannot be loaded as warnings", func() {
+		type Status string
+		type Ticket struct {
+			Status   Status `json:"status"`
+			Previous Status `json:"previous"`
+		}
+		generator := generators.NewIoTsGenerator()
+		_, diagnostics, err := generator.GenerateWithDiagnostics(Ticket{})
+		Expect(err).To(BeNil())
+
+		Expect(diagnostics).To(HaveLen(1))
+		Expect(diagnostics[0].Severity).To(Equal(generators.SeverityWarning))
+		Expect(diagnostics[0].FieldPath).To(Equal("Ticket"))
+		Expect(diagnostics[0]).To(unloadedPackage)
+		Expect(diagnostics[0].Err).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,235 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"golang.org/x/tools/go/packages"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// directivePrefix starts the comment directives read from the source, e.g.
+//
+//	//iots:export
+//	type Customer struct {
+//		Name  string `json:"name"`
+//		Notes string `json:"notes"` //iots:optional
+//		Token string `json:"token"` //iots:ignore
+//	}
+const directivePrefix = "//iots:"
+
+// ExportedType is a type marked with the //iots:export directive
+type ExportedType struct {
+	PkgPath string
+	// PkgName is the name of the package, e.g. main for commands, which cannot be imported
+	PkgName string
+	Name    string
+	// Position is where the type is declared
+	Position token.Position
+	// Generic reports whether the type has type parameters, so it cannot be generated as is
+	Generic bool
+}
+
+// fieldDirectives holds the directives of a struct field
+type fieldDirectives struct {
+	ignore   bool
+	optional bool
+}
+
+// typeDirectives holds what the directives of a package's source declare
+type typeDirectives struct {
+	exports []ExportedType
+	// fields maps type names to the directives of their fields by Go field name
+	fields map[string]map[string]fieldDirectives
+	// invalid lists the directives that could not be parsed, with their position
+	invalid []string
+}
+
+// ExportedTypes loads the packages matching the patterns, e.g. ./..., and returns the types
+// marked with //iots:export, ordered by package path and declaration.
+func (c *PackageCache) ExportedTypes(patterns ...string) ([]ExportedType, error) {
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName}, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("loading packages %s: %w", strings.Join(patterns, " "), err)
+	}
+	var pkgPaths []string
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			return nil, fmt.Errorf("loading package %s: %s", pkg.PkgPath, e.Error())
+		}
+		pkgPaths = append(pkgPaths, pkg.PkgPath)
+	}
+	sort.Strings(pkgPaths)
+
+	var exported []ExportedType
+	var invalid []string
+	for _, pkgPath := range pkgPaths {
+		idx := c.index(pkgPath)
+		if idx.err != nil {
+			return nil, idx.err
+		}
+		exported = append(exported, idx.directives.exports...)
+		invalid = append(invalid, idx.directives.invalid...)
+	}
+	if len(invalid) > 0 {
+		return exported, fmt.Errorf("invalid directives: %s", strings.Join(invalid, "; "))
+	}
+	return exported, nil
+}
+
+// ExportedTypes returns the types marked with //iots:export using the default package cache
+func ExportedTypes(patterns ...string) ([]ExportedType, error) {
+	return defaultPackageCache.ExportedTypes(patterns...)
+}
+
+// fieldDirectives returns the directives of the fields of a named struct by Go field name.
+// The error reports why the struct's package could not be loaded.
+func (c *PackageCache) fieldDirectives(t reflect.Type) (map[string]fieldDirectives, error) {
+	if t.PkgPath() == "" || t.Name() == "" {
+		return nil, nil
+	}
+	idx := c.index(t.PkgPath())
+	return idx.directives.fields[t.Name()], idx.err
+}
+
+// parseTypeDirectives collects the //iots:export directives of the package level types and the
+// //iots:ignore and //iots:optional directives of their struct fields
+func parseTypeDirectives(pkg *packages.Package) typeDirectives {
+	parsed := typeDirectives{fields: make(map[string]map[string]fieldDirectives)}
+	invalid := func(pos token.Pos, directive string) {
+		parsed.invalid = append(parsed.invalid, fmt.Sprintf("%s: unknown directive %s", pkg.Fset.Position(pos), directive))
+	}
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				groups := []*ast.CommentGroup{typeSpec.Doc}
+				if len(gen.Specs) == 1 {
+					groups = append(groups, gen.Doc)
+				}
+				for _, d := range commentDirectives(groups...) {
+					if d.name != "export" {
+						invalid(d.pos, d.text)
+						continue
+					}
+					parsed.exports = append(parsed.exports, ExportedType{
+						PkgPath:  pkg.PkgPath,
+						PkgName:  pkg.Name,
+						Name:     typeSpec.Name.Name,
+						Position: pkg.Fset.Position(typeSpec.Pos()),
+						Generic:  typeSpec.TypeParams != nil && len(typeSpec.TypeParams.List) > 0,
+					})
+				}
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				for _, field := range structType.Fields.List {
+					var directives fieldDirectives
+					for _, d := range commentDirectives(field.Doc, field.Comment) {
+						switch d.name {
+						case "ignore":
+							directives.ignore = true
+						case "optional":
+							directives.optional = true
+						default:
+							invalid(d.pos, d.text)
+						}
+					}
+					if directives == (fieldDirectives{}) {
+						continue
+					}
+					if parsed.fields[typeSpec.Name.Name] == nil {
+						parsed.fields[typeSpec.Name.Name] = make(map[string]fieldDirectives)
+					}
+					for _, name := range astFieldNames(field) {
+						parsed.fields[typeSpec.Name.Name][name] = directives
+					}
+				}
+			}
+		}
+	}
+	return parsed
+}
+
+// commentDirective is a single //iots: directive
+type commentDirective struct {
+	name string
+	text string
+	pos  token.Pos
+}
+
+// commentDirectives returns the //iots: directives of the comment groups. Go hides directives
+// from CommentGroup.Text, so the raw comments are read.
+func commentDirectives(groups ...*ast.CommentGroup) []commentDirective {
+	var directives []commentDirective
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		for _, comment := range group.List {
+			if !strings.HasPrefix(comment.Text, directivePrefix) {
+				continue
+			}
+			name := strings.TrimPrefix(comment.Text, directivePrefix)
+			if fields := strings.Fields(name); len(fields) > 0 {
+				name = fields[0]
+			}
+			directives = append(directives, commentDirective{name: name, text: comment.Text, pos: comment.Pos()})
+		}
+	}
+	return directives
+}
+
+// astFieldNames returns the Go names of a field declaration, which for an embedded field
+// is the name of its type
+func astFieldNames(field *ast.Field) []string {
+	if len(field.Names) > 0 {
+		names := make([]string, len(field.Names))
+		for i, name := range field.Names {
+			names[i] = name.Name
+		}
+		return names
+	}
+	expr := field.Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.SelectorExpr:
+			expr = e.Sel
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return []string{e.Name}
+		default:
+			return nil
+		}
+	}
+}
+
+// withDirectives folds the directives of a field into its `iots` tag, so //iots:ignore and
+// //iots:optional behave like iots:"-" and iots:"optional"
+func withDirectives(field reflect.StructField, directives fieldDirectives) reflect.StructField {
+	value, _ := field.Tag.Lookup("iots")
+	switch {
+	case directives.ignore:
+		value = "-"
+	case directives.optional && strings.TrimSpace(value) == "":
+		value = "optional"
+	case directives.optional && strings.TrimSpace(value) != "-":
+		value += ",optional"
+	}
+	// Lookup returns the first occurrence of a key, so the merged value shadows the original
+	field.Tag = reflect.StructTag("iots:" + strconv.Quote(value) + " " + string(field.Tag))
+	return field
+}
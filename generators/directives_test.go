@@ -0,0 +1,57 @@
+package generators_test
+
+import (
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/fixtures"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/generators"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Source Directives", func() {
+	const fixturesPath = "github.com/VictorMarcolino/golang-struct-to-io-ts/fixtures"
+
+	It("should discover the types marked with //iots:export", func() {
+		exported, err := generators.NewPackageCache().ExportedTypes(fixturesPath)
+		Expect(err).To(BeNil())
+
+		names := map[string]bool{}
+		for _, e := range exported {
+			Expect(e.PkgPath).To(Equal(fixturesPath))
+			Expect(e.PkgName).To(Equal("fixtures"))
+			Expect(e.Position.Filename).To(HaveSuffix("directives_examples.go"))
+			names[e.Name] = e.Generic
+		}
+		Expect(names).To(Equal(map[string]bool{
+			"Shipment": false,
+			"Labels":   false,
+			"Tracking": false,
+			"Box":      true,
+		}))
+	})
+
+	It("should apply //iots:ignore and //iots:optional to struct fields", func() {
+		result, err := generators.NewIoTsGenerator().GenerateAll(fixtures.Shipment{}, fixtures.Tracking{})
+
+		expected := `
+import * as t from 'io-ts';
+import { NonEmptyString } from 'io-ts-types';
+
+export const ShipmentC = t.type({
+  id: t.string,
+  carrier: t.union([t.string, t.undefined]),
+  weight: t.number,
+  note: t.union([t.string, t.undefined]),
+  labels: t.array(t.string),
+});
+export type Shipment = t.TypeOf<typeof ShipmentC>;
+
+export const TrackingC = t.type({
+  code: t.union([NonEmptyString, t.undefined]),
+});
+export type Tracking = t.TypeOf<typeof TrackingC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+})
@@ -1,135 +1,369 @@
 package generators
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"go/ast"
 	"go/constant"
+	"go/token"
 	"go/types"
 	"golang.org/x/tools/go/packages"
-	"log"
+	"math"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 )
 
+// EnumConstant describes a typed constant declared for an enum type.
+type EnumConstant struct {
+	Name  string
+	Value interface{}
+	// Position is where the constant is declared, for go-to-definition
+	Position token.Position
+	// Flag reports whether the constant is declared with a left shift, e.g. 1 << iota
+	Flag bool
+	// Doc is the doc comment, or else the line comment, of the constant
+	Doc string
+	// JSON is the value as serialized by the MarshalJSON or MarshalText method of the type,
+	// or nil when the type has neither
+	JSON interface{}
+}
+
+// jsonValue returns the value the constant has in JSON payloads
+func (c EnumConstant) jsonValue() interface{} {
+	if c.JSON != nil {
+		return c.JSON
+	}
+	return c.Value
+}
+
+// PackageCache loads each Go package at most once and indexes its typed constants
+// by named type, so enum lookups are answered from memory. It also holds the enums
+// registered explicitly with RegisterEnum. It is safe for concurrent use.
+type PackageCache struct {
+	mu         sync.Mutex
+	packages   map[string]*packageIndex
+	registered map[reflect.Type][]EnumConstant
+}
+
+// packageIndex holds the enum constants and source directives of a single loaded package
+type packageIndex struct {
+	once       sync.Once
+	enums      map[string][]EnumConstant
+	directives typeDirectives
+	err        error
+}
+
+// defaultPackageCache is shared by the package level helpers and by generators
+// that are not given a cache of their own.
+var defaultPackageCache = NewPackageCache()
+
+// NewPackageCache creates an empty PackageCache
+func NewPackageCache() *PackageCache {
+	return &PackageCache{
+		packages:   make(map[string]*packageIndex),
+		registered: make(map[reflect.Type][]EnumConstant),
+	}
+}
+
+// EnumConstants returns the constants declared for the given type, in declaration order.
+// Registered enums are consulted first, then types implementing Enumer, then the package source.
+// The error reports why the type's package could not be loaded.
+func (c *PackageCache) EnumConstants(t reflect.Type) ([]EnumConstant, error) {
+	if t == nil || t.PkgPath() == "" || t.Name() == "" || !canHaveConstants(t.Kind()) {
+		return nil, nil
+	}
+	c.mu.Lock()
+	registered, ok := c.registered[t]
+	c.mu.Unlock()
+	if ok {
+		return registered, nil
+	}
+	if constants, ok := enumerConstants(t); ok {
+		return constants, nil
+	}
+	idx := c.index(t.PkgPath())
+	return idx.enums[t.Name()], idx.err
+}
+
 // IsEnumType checks if the given reflect.Type has any matching constants in its package.
-func IsEnumType(t reflect.Type) bool {
-	return len(GetEnumConstantsAsMap(t)) > 0
+func (c *PackageCache) IsEnumType(t reflect.Type) bool {
+	constants, _ := c.EnumConstants(t)
+	return len(constants) > 0
 }
 
-// GetEnumConstantsAsMap extracts enum constants associated with the given type
-// and returns a map of "constant name" -> "constant value"
-func GetEnumConstantsAsMap(t reflect.Type) map[string]interface{} {
-	if t == nil || t.PkgPath() == "" || t.Name() == "" {
-		return nil
+// index returns the index of the package, loading it on first use
+func (c *PackageCache) index(pkgPath string) *packageIndex {
+	c.mu.Lock()
+	idx, ok := c.packages[pkgPath]
+	if !ok {
+		idx = &packageIndex{}
+		c.packages[pkgPath] = idx
 	}
+	c.mu.Unlock()
+
+	idx.once.Do(func() {
+		idx.load(pkgPath)
+	})
+	return idx
+}
+
+// load runs packages.Load for the package, indexes every typed constant by its named type
+// and collects the //iots: directives
+func (idx *packageIndex) load(pkgPath string) {
 	cfg := &packages.Config{
-		Mode: packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo,
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
 	}
-	pkgs, err := packages.Load(cfg, t.PkgPath())
+	pkgs, err := packages.Load(cfg, pkgPath)
 	if err != nil {
-		log.Printf("Error loading package: %v\n", err)
-		return nil
+		idx.err = fmt.Errorf("loading package %s: %w", pkgPath, err)
+		return
 	}
-	if packages.PrintErrors(pkgs) > 0 {
-		return nil
+	var loadErrors []string
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, e := range pkg.Errors {
+			loadErrors = append(loadErrors, e.Error())
+		}
+	})
+	if len(loadErrors) > 0 {
+		idx.err = fmt.Errorf("loading package %s: %s", pkgPath, strings.Join(loadErrors, "; "))
+		return
 	}
-	results := make(map[string]interface{})
+	idx.enums = make(map[string][]EnumConstant)
 	for _, pkg := range pkgs {
+		if pkg.PkgPath == pkgPath {
+			idx.directives = parseTypeDirectives(pkg)
+		}
+		syntax := constantsSyntax(pkg.Syntax)
 		scope := pkg.Types.Scope()
 		for _, name := range scope.Names() {
-			obj := scope.Lookup(name)
-			if c, ok := obj.(*types.Const); ok {
-				if named, ok := c.Type().(*types.Named); ok && named.Obj().Name() == t.Name() {
-					val := c.Val() // constant.Value
-
-					switch val.Kind() {
-					case constant.String:
-						// For string constants
-						results[c.Name()] = constant.StringVal(val)
-					case constant.Bool:
-						results[c.Name()] = constant.BoolVal(val)
-					case constant.Int:
-						// For integer constants (returning int64 if it fits)
-						i64, _ := constant.Int64Val(val)
-						results[c.Name()] = i64
-					case constant.Float:
-						// For float constants
-						f, _ := constant.Float64Val(val)
-						results[c.Name()] = f
-					default:
-						// Fallback: store exact string
-						results[c.Name()] = val.ExactString()
+			c, ok := scope.Lookup(name).(*types.Const)
+			if !ok {
+				continue
+			}
+			named, ok := c.Type().(*types.Named)
+			if !ok || named.Obj().Pkg() != pkg.Types {
+				continue
+			}
+			typeName := named.Obj().Name()
+			idx.enums[typeName] = append(idx.enums[typeName], EnumConstant{
+				Name:     c.Name(),
+				Value:    constantValue(c.Val()),
+				Position: pkg.Fset.Position(c.Pos()),
+				Flag:     syntax[c.Name()].shifted,
+				Doc:      syntax[c.Name()].doc,
+			})
+		}
+	}
+	for _, constants := range idx.enums {
+		sort.SliceStable(constants, func(i, j int) bool {
+			if constants[i].Position.Filename != constants[j].Position.Filename {
+				return constants[i].Position.Filename < constants[j].Position.Filename
+			}
+			return constants[i].Position.Offset < constants[j].Position.Offset
+		})
+	}
+}
+
+// constantSyntax holds what the source tells about a constant beyond its value
+type constantSyntax struct {
+	shifted bool
+	doc     string
+}
+
+// constantsSyntax indexes the package level constants by name. A constant is shifted when its value
+// expression, or the one it repeats from a previous line, contains a left shift. Its doc is the doc
+// comment, or else the line comment, of its declaration.
+func constantsSyntax(files []*ast.File) map[string]constantSyntax {
+	syntax := make(map[string]constantSyntax)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			var values []ast.Expr
+			for _, spec := range gen.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				if len(valueSpec.Values) > 0 {
+					values = valueSpec.Values
+				}
+				doc := commentText(valueSpec.Doc)
+				if doc == "" {
+					doc = commentText(valueSpec.Comment)
+				}
+				if doc == "" && len(gen.Specs) == 1 {
+					doc = commentText(gen.Doc)
+				}
+				for i, name := range valueSpec.Names {
+					syntax[name.Name] = constantSyntax{
+						shifted: i < len(values) && containsShift(values[i]),
+						doc:     doc,
 					}
 				}
 			}
 		}
 	}
-	return results
+	return syntax
 }
 
-func GetIoTsEnumText(t reflect.Type) string {
-	constants := GetEnumConstantsAsMap(t)
-	if len(constants) == 0 {
-		panic("No constants found for type")
-	}
-
-	// Sort the constant names for stable output (optional).
-	names := make([]string, 0, len(constants))
-	for name := range constants {
-		names = append(names, name)
-	}
-	sort.Strings(names)
-
-	constLines := make([]string, 0, len(constants))
-	literalLines := make([]string, 0, len(constants))
-
-	for _, name := range names {
-		value := constants[name]
-
-		// Distinguish string vs. numeric
-		switch v := value.(type) {
-		case string:
-			// Strings get quoted
-			constLines = append(constLines,
-				fmt.Sprintf(`export const %s%s = "%s" as const;`, t.Name(), name, v),
-			)
-			literalLines = append(literalLines,
-				fmt.Sprintf(`t.literal(%s%s)`, t.Name(), name),
-			)
-
-		case int64, int, float64:
-			// Numeric constants: no quotes
-			constLines = append(constLines,
-				fmt.Sprintf(`export const %s%s = %v as const;`, t.Name(), name, v),
-			)
-			literalLines = append(literalLines,
-				fmt.Sprintf(`t.literal(%s%s)`, t.Name(), name),
-			)
-
-		default:
-			// Fallback to string representation, if needed
-			constVal := fmt.Sprintf("%v", v)
-			constLines = append(constLines,
-				fmt.Sprintf(`export const %s%s = "%s" as const;`, t.Name(), name, constVal),
-			)
-			literalLines = append(literalLines,
-				fmt.Sprintf(`t.literal(%s%s)`, t.Name(), name),
-			)
+// commentText joins the lines of a comment group with single spaces
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(group.Text()), " ")
+}
+
+// containsShift reports whether the expression uses the << operator
+func containsShift(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if binary, ok := n.(*ast.BinaryExpr); ok && binary.Op == token.SHL {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// constantValue converts a constant.Value into the closest Go value
+func constantValue(val constant.Value) interface{} {
+	switch val.Kind() {
+	case constant.String:
+		// For string constants
+		return constant.StringVal(val)
+	case constant.Bool:
+		return constant.BoolVal(val)
+	case constant.Int:
+		// For integer constants (returning int64 if it fits, else uint64)
+		if i64, exact := constant.Int64Val(val); exact {
+			return i64
+		}
+		if u64, exact := constant.Uint64Val(val); exact {
+			return u64
+		}
+		return val.ExactString()
+	case constant.Float:
+		// For float constants
+		f, _ := constant.Float64Val(val)
+		return f
+	default:
+		// Fallback: store exact string
+		return val.ExactString()
+	}
+}
+
+// marshalEnumConstants fills the JSON value of each constant when the type implements
+// json.Marshaler or encoding.TextMarshaler, like encoding/json does for an addressable value
+func marshalEnumConstants(t reflect.Type, constants []EnumConstant) ([]EnumConstant, error) {
+	if !hasMarshaler(t) {
+		return constants, nil
+	}
+	marshaled := make([]EnumConstant, len(constants))
+	for i, c := range constants {
+		v := reflect.ValueOf(c.Value)
+		if !v.IsValid() || !v.Type().ConvertibleTo(t) {
+			return nil, fmt.Errorf("constant %s of %s has an unexpected value %v", c.Name, typePath(t), c.Value)
+		}
+		value := reflect.New(t)
+		value.Elem().Set(v.Convert(t))
+		jsonValue, err := marshalEnumValue(value.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("marshaling constant %s of %s: %w", c.Name, typePath(t), err)
 		}
+		c.JSON = jsonValue
+		marshaled[i] = c
 	}
+	return marshaled, nil
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
 
-	allConsts := strings.Join(constLines, "\n")
-	allLiterals := strings.Join(literalLines, ",\n")
+// hasMarshaler reports whether encoding/json marshals values of the type with a MarshalJSON
+// or MarshalText method
+func hasMarshaler(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	return ptr.Implements(jsonMarshalerType) || ptr.Implements(textMarshalerType)
+}
 
-	typeDef := fmt.Sprintf(`%s
+// marshalEnumValue invokes the marshaler of a value and decodes the result into a literal value
+func marshalEnumValue(value interface{}) (interface{}, error) {
+	if marshaler, ok := value.(json.Marshaler); ok {
+		data, err := marshaler.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, err
+		}
+		switch v := decoded.(type) {
+		case string, bool:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				return int64(v), nil
+			}
+			return v, nil
+		}
+		return nil, fmt.Errorf("MarshalJSON returned %s, which is not a string, number or boolean", data)
+	}
+	text, err := value.(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
 
-export const %sC = t.union([
-%s
-]);
+// canHaveConstants reports whether a type of the given kind can have constants declared
+func canHaveConstants(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
 
-export type %s = t.TypeOf<typeof %sC>;
+// IsEnumType checks if the given reflect.Type has any matching constants in its package.
+func IsEnumType(t reflect.Type) bool {
+	return defaultPackageCache.IsEnumType(t)
+}
 
-`, allConsts, t.Name(), allLiterals, t.Name(), t.Name())
+// GetEnumConstantsAsMap extracts enum constants associated with the given type
+// and returns a map of "constant name" -> "constant value"
+func GetEnumConstantsAsMap(t reflect.Type) (map[string]interface{}, error) {
+	constants, err := defaultPackageCache.EnumConstants(t)
+	if err != nil || len(constants) == 0 {
+		return nil, err
+	}
+	results := make(map[string]interface{}, len(constants))
+	for _, c := range constants {
+		results[c.Name] = c.Value
+	}
+	return results, nil
+}
 
-	return typeDef
+// GetIoTsEnumText renders the io-ts union for the constants of the given type
+func GetIoTsEnumText(t reflect.Type) (string, error) {
+	constants, err := defaultPackageCache.EnumConstants(t)
+	if err != nil {
+		return "", err
+	}
+	if len(constants) == 0 {
+		return "", fmt.Errorf("no constants found for type %s", typePath(t))
+	}
+	if constants, err = marshalEnumConstants(t, constants); err != nil {
+		return "", err
+	}
+	text, _, err := renderEnum(t, constants, EnumOptions{})
+	return text, err
 }
@@ -0,0 +1,84 @@
+package generators_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/fixtures"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/generators"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IO-TS:Package Cache", func() {
+	It("should index enum constants in declaration order", func() {
+		cache := generators.NewPackageCache()
+		constants, err := cache.EnumConstants(reflect.TypeOf(fixtures.ExampleString("")))
+		Expect(err).To(BeNil())
+
+		names := make([]string, 0, len(constants))
+		for _, c := range constants {
+			names = append(names, c.Name)
+		}
+		Expect(names).To(Equal([]string{"ExampleString1", "ExampleStringTwo", "ExampleString3"}))
+		Expect(constants[0].Position.Filename).To(HaveSuffix("enumerate_examples.go"))
+	})
+
+	It("should not report struct types as enums", func() {
+		cache := generators.NewPackageCache()
+		Expect(cache.IsEnumType(reflect.TypeOf(fixtures.Example{}))).To(BeFalse())
+		Expect(cache.IsEnumType(reflect.TypeOf(fixtures.ExampleInt(0)))).To(BeTrue())
+	})
+
+	It("should keep uint64 constants that do not fit in an int64", func() {
+		constants, err := generators.NewPackageCache().EnumConstants(reflect.TypeOf(fixtures.Big(0)))
+		Expect(err).To(BeNil())
+		Expect(constants).To(HaveLen(2))
+		Expect(constants[1].Value).To(Equal(uint64(18446744073709551615)))
+
+		text, err := generators.GetIoTsEnumText(reflect.TypeOf(fixtures.Big(0)))
+		Expect(err).To(BeNil())
+		Expect(text).To(ContainSubstring("export const BigBigMax = 18446744073709551615 as const;"))
+	})
+
+	It("should produce the same output when generators share a cache", func() {
+		cache := generators.NewPackageCache()
+		first, err := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{PackageCache: cache}).Generate(fixtures.Example{})
+		Expect(err).To(BeNil())
+		second, err := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{PackageCache: cache}).Generate(fixtures.Example{})
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(second)).To(Equal(utils.NormalizeWhitespace(first)))
+	})
+})
+
+func BenchmarkGenerateEnums(b *testing.B) {
+	b.Run("cold cache", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			options := generators.TypeScriptGeneratorOptions{PackageCache: generators.NewPackageCache()}
+			if _, err := generators.NewIoTsGenerator(options).Generate(fixtures.Example{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("warm cache", func(b *testing.B) {
+		options := generators.TypeScriptGeneratorOptions{PackageCache: generators.NewPackageCache()}
+		options.PackageCache.IsEnumType(reflect.TypeOf(fixtures.ExampleString("")))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := generators.NewIoTsGenerator(options).Generate(fixtures.Example{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkIsEnumType(b *testing.B) {
+	cache := generators.NewPackageCache()
+	t := reflect.TypeOf(fixtures.ExampleString(""))
+	cache.IsEnumType(t)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.IsEnumType(t)
+	}
+}
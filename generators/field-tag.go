@@ -0,0 +1,126 @@
+package generators
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// fieldTag is the parsed form of the struct tag that names a field, e.g. `json` or `yaml`
+type fieldTag struct {
+	// name is the property name, or the Go field name when the tag leaves it empty
+	name string
+	// skip is set for untagged fields and fields tagged "-"
+	skip bool
+	// untagged is set when the field carries none of the tag keys, or an empty tag
+	untagged bool
+	// explicit is set when the tag gives the name
+	explicit bool
+	// omitEmpty is set by the omitempty and omitzero options
+	omitEmpty bool
+	// inline merges the fields of the field's struct into the parent; mapstructure calls it squash
+	inline bool
+	// asString is set by the string option of the json tag: numbers and booleans are encoded as JSON strings
+	asString bool
+}
+
+// parseFieldTag parses the first tag of the field found among keys, like encoding/json parses
+// the json tag. Fields carrying none of the keys are skipped. keys defaults to json.
+func parseFieldTag(field reflect.StructField, keys []string) fieldTag {
+	if len(keys) == 0 {
+		keys = []string{"json"}
+	}
+	for _, key := range keys {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			return parseTagValue(field, key, tag)
+		}
+	}
+	return fieldTag{name: field.Name, skip: true, untagged: true}
+}
+
+// parseTagValue parses the value of a tag with the given key
+func parseTagValue(field reflect.StructField, key, tag string) fieldTag {
+	if tag == "" {
+		return fieldTag{name: field.Name, skip: true, untagged: true}
+	}
+	if tag == "-" {
+		return fieldTag{name: field.Name, skip: true}
+	}
+	parts := strings.Split(tag, ",")
+	parsed := fieldTag{name: parts[0], explicit: parts[0] != ""}
+	if parsed.name == "" {
+		parsed.name = field.Name
+	}
+	for _, option := range parts[1:] {
+		switch option {
+		case "omitempty", "omitzero":
+			parsed.omitEmpty = true
+		case "inline", "squash":
+			parsed.inline = true
+		case "string":
+			parsed.asString = key == "json"
+		}
+	}
+	return parsed
+}
+
+// FieldNamer names a field from its Go name, for fields whose tag gives no name
+type FieldNamer func(goName string) string
+
+// FieldNameGo keeps the Go field name, e.g. UserID
+func FieldNameGo(goName string) string {
+	return goName
+}
+
+// FieldNameCamelCase names fields in camelCase, e.g. userId
+func FieldNameCamelCase(goName string) string {
+	words := splitWords(goName)
+	for i, word := range words {
+		runes := []rune(strings.ToLower(word))
+		if i > 0 && len(runes) > 0 {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		words[i] = string(runes)
+	}
+	return strings.Join(words, "")
+}
+
+// FieldNameSnakeCase names fields in snake_case, e.g. user_id
+func FieldNameSnakeCase(goName string) string {
+	return strings.ToLower(strings.Join(splitWords(goName), "_"))
+}
+
+// FieldNameKebabCase names fields in kebab-case, e.g. user-id
+func FieldNameKebabCase(goName string) string {
+	return strings.ToLower(strings.Join(splitWords(goName), "-"))
+}
+
+// splitWords splits a Go identifier into words, keeping acronyms and trailing digits together,
+// e.g. HTTPServerID2 becomes HTTP, Server, ID2
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		switch {
+		case cur == '_':
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+		case unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			// userID -> user, ID
+			words = append(words, string(runes[start:i]))
+			start = i
+		case unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// HTTPServer -> HTTP, Server
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
@@ -0,0 +1,330 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// EnumStyle selects how the codec of an enum type is emitted
+type EnumStyle int
+
+const (
+	// EnumStyleUnion emits a t.union of t.literal codecs
+	EnumStyleUnion EnumStyle = iota
+	// EnumStyleKeyof emits t.keyof({...}) for string enums; other enums fall back to EnumStyleUnion
+	EnumStyleKeyof
+)
+
+// EnumNaming selects how enum constants are named in TypeScript
+type EnumNaming int
+
+const (
+	// EnumNamingConcat prefixes every constant with its type name, e.g. ExampleStringExampleStringTwo
+	EnumNamingConcat EnumNaming = iota
+	// EnumNamingStripPrefix prefixes constants with their type name unless they already start with it,
+	// e.g. ExampleStringTwo and ExampleIntCode1
+	EnumNamingStripPrefix
+	// EnumNamingVerbatim keeps the Go constant names, e.g. ExampleStringTwo and Code1
+	EnumNamingVerbatim
+	// EnumNamingNamespace groups the constants in an object named after the type, e.g. ExampleString.Two
+	EnumNamingNamespace
+)
+
+// EnumOrder selects the order of enum constants in the output
+type EnumOrder int
+
+const (
+	// EnumOrderName sorts constants by Go constant name
+	EnumOrderName EnumOrder = iota
+	// EnumOrderDeclaration keeps the source declaration order, e.g. iota order
+	EnumOrderDeclaration
+)
+
+// EnumFlags selects whether an integer enum is treated as a set of bit flags
+type EnumFlags int
+
+const (
+	// EnumFlagsAuto treats an enum as bit flags when one of its constants is declared with a shift, e.g. 1 << iota
+	EnumFlagsAuto EnumFlags = iota
+	// EnumFlagsAlways treats every non-negative integer enum as bit flags
+	EnumFlagsAlways
+	// EnumFlagsNever always emits a union of the declared values
+	EnumFlagsNever
+)
+
+// EnumLabelSource selects where the labels of an enum label map come from
+type EnumLabelSource int
+
+const (
+	// EnumLabelName uses the Go constant name
+	EnumLabelName EnumLabelSource = iota
+	// EnumLabelComment uses the doc or line comment of each constant, falling back to its name
+	EnumLabelComment
+	// EnumLabelString calls the String method of the enum type on each value, falling back to the constant name
+	EnumLabelString
+)
+
+// EnumOptions configures the output generated for an enum type
+type EnumOptions struct {
+	Style  EnumStyle
+	Naming EnumNaming
+	Order  EnumOrder
+	// Flags selects when a codec accepting any combination of the constants is emitted instead of a union
+	Flags EnumFlags
+	// Values exports a readonly array of the enum values in declaration order, e.g. ExampleStringValues
+	Values bool
+	// Labels exports a Record from each enum value to its label, e.g. ExampleStringLabels
+	Labels bool
+	// LabelSource selects where the labels come from
+	LabelSource EnumLabelSource
+}
+
+// renderEnum renders the exported constants, codec, type and helpers of an enum.
+// It also returns the top-level value names the output declares.
+func renderEnum(t reflect.Type, constants []EnumConstant, options EnumOptions) (string, []string, error) {
+	// Constants arrive in declaration order; sort them by name unless that order is requested
+	sorted := make([]EnumConstant, len(constants))
+	copy(sorted, constants)
+	if options.Order == EnumOrderName {
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Name < sorted[j].Name
+		})
+	}
+
+	refs, err := enumReferences(t, constants, options.Naming)
+	if err != nil {
+		return "", nil, err
+	}
+	names := []string{t.Name() + "C"}
+
+	constLines := make([]string, 0, len(sorted))
+	literalLines := make([]string, 0, len(sorted))
+	for _, c := range sorted {
+		if options.Naming == EnumNamingNamespace {
+			constLines = append(constLines,
+				fmt.Sprintf(`  %s: %s,`, formatPropertyName(strings.TrimPrefix(refs[c.Name], t.Name()+".")), enumLiteral(c.jsonValue())),
+			)
+		} else {
+			constLines = append(constLines,
+				fmt.Sprintf(`export const %s = %s as const;`, refs[c.Name], enumLiteral(c.jsonValue())),
+			)
+			names = append(names, refs[c.Name])
+		}
+		literalLines = append(literalLines,
+			fmt.Sprintf(`t.literal(%s)`, refs[c.Name]),
+		)
+	}
+
+	var sb strings.Builder
+	if options.Naming == EnumNamingNamespace {
+		sb.WriteString(fmt.Sprintf("export const %s = {\n%s\n} as const;", t.Name(), strings.Join(constLines, "\n")))
+		names = append(names, t.Name())
+	} else {
+		sb.WriteString(strings.Join(constLines, "\n"))
+	}
+	sb.WriteString("\n\n")
+
+	if isFlagEnum(constants, options.Flags) {
+		sb.WriteString(renderFlagCodec(t, constants))
+	} else if options.Style == EnumStyleKeyof && isStringEnum(constants) {
+		sb.WriteString(fmt.Sprintf("export const %sC = t.keyof({\n", t.Name()))
+		for _, c := range uniqueEnumValues(sorted) {
+			sb.WriteString(fmt.Sprintf("  %s: null,\n", enumLiteral(c.jsonValue())))
+		}
+		sb.WriteString("});\n\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("export const %sC = t.union([\n%s\n]);\n\n", t.Name(), strings.Join(literalLines, ",\n")))
+	}
+	sb.WriteString(fmt.Sprintf("export type %s = t.TypeOf<typeof %sC>;\n\n", t.Name(), t.Name()))
+	if isFlagEnum(constants, options.Flags) {
+		sb.WriteString(renderFlagHelpers(t))
+		names = append(names, t.Name()+"Mask", "has"+t.Name(), "combine"+t.Name())
+	}
+
+	if options.Values {
+		sb.WriteString(fmt.Sprintf("export const %sValues = [\n", t.Name()))
+		for _, c := range uniqueEnumValues(constants) {
+			sb.WriteString(fmt.Sprintf("  %s,\n", refs[c.Name]))
+		}
+		sb.WriteString("] as const;\n\n")
+		names = append(names, t.Name()+"Values")
+	}
+	if options.Labels {
+		sb.WriteString(fmt.Sprintf("export const %sLabels: Record<%s, string> = {\n", t.Name(), t.Name()))
+		for _, c := range uniqueEnumValues(constants) {
+			sb.WriteString(fmt.Sprintf("  [%s]: %s,\n", refs[c.Name], tsString(enumLabel(t, c, options.LabelSource))))
+		}
+		sb.WriteString("};\n\n")
+		names = append(names, t.Name()+"Labels")
+	}
+	return sb.String(), names, nil
+}
+
+// renderFlagCodec renders the mask of every flag and a codec accepting any combination of them
+func renderFlagCodec(t reflect.Type, constants []EnumConstant) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("export const %sMask = %d;\n\n", t.Name(), flagMask(constants)))
+	sb.WriteString(fmt.Sprintf("export const %sC = new t.Type<number, number, unknown>(\n", t.Name()))
+	sb.WriteString(fmt.Sprintf("  %s,\n", tsString(t.Name())))
+	sb.WriteString(fmt.Sprintf("  (u): u is number => typeof u === \"number\" && Number.isInteger(u) && u >= 0 && (u & ~%sMask) === 0,\n", t.Name()))
+	sb.WriteString(fmt.Sprintf("  (u, c) => (%sC.is(u) ? t.success(u) : t.failure(u, c)),\n", t.Name()))
+	sb.WriteString("  t.identity\n")
+	sb.WriteString(");\n\n")
+	return sb.String()
+}
+
+// renderFlagHelpers renders the functions testing and combining flags
+func renderFlagHelpers(t reflect.Type) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("export const has%s = (value: %s, flag: %s): boolean => (value & flag) === flag;\n\n", t.Name(), t.Name(), t.Name()))
+	sb.WriteString(fmt.Sprintf("export const combine%s = (...flags: %s[]): %s => flags.reduce((acc, flag) => acc | flag, 0);\n\n", t.Name(), t.Name(), t.Name()))
+	return sb.String()
+}
+
+// isFlagEnum reports whether the enum is emitted as bit flags: every constant must be a non-negative integer
+func isFlagEnum(constants []EnumConstant, flags EnumFlags) bool {
+	if flags == EnumFlagsNever || len(constants) == 0 {
+		return false
+	}
+	shifted := false
+	for _, c := range constants {
+		if v, ok := c.jsonValue().(int64); !ok || v < 0 {
+			return false
+		}
+		shifted = shifted || c.Flag
+	}
+	return flags == EnumFlagsAlways || shifted
+}
+
+// flagMask combines the values of every flag
+func flagMask(constants []EnumConstant) int64 {
+	var mask int64
+	for _, c := range constants {
+		if v, ok := c.jsonValue().(int64); ok {
+			mask |= v
+		}
+	}
+	return mask
+}
+
+// enumLabel returns the label of a constant from the given source, falling back to its name
+func enumLabel(t reflect.Type, c EnumConstant, source EnumLabelSource) string {
+	switch source {
+	case EnumLabelComment:
+		if c.Doc != "" {
+			return c.Doc
+		}
+	case EnumLabelString:
+		v := reflect.ValueOf(c.Value)
+		if v.IsValid() && v.Type().ConvertibleTo(t) {
+			if stringer, ok := v.Convert(t).Interface().(fmt.Stringer); ok {
+				return stringer.String()
+			}
+		}
+	}
+	return c.Name
+}
+
+// enumReferences returns the TypeScript expression that refers to each constant, keyed by Go constant name
+func enumReferences(t reflect.Type, constants []EnumConstant, naming EnumNaming) (map[string]string, error) {
+	refs := make(map[string]string, len(constants))
+	owners := make(map[string]string, len(constants))
+	for _, c := range constants {
+		stripped := strings.TrimPrefix(c.Name, t.Name())
+		switch naming {
+		case EnumNamingStripPrefix:
+			refs[c.Name] = t.Name() + stripped
+		case EnumNamingVerbatim:
+			refs[c.Name] = c.Name
+		case EnumNamingNamespace:
+			if !isIdentifier(stripped) {
+				stripped = c.Name
+			}
+			refs[c.Name] = t.Name() + "." + stripped
+		default:
+			refs[c.Name] = t.Name() + c.Name
+		}
+		if owner, ok := owners[refs[c.Name]]; ok {
+			return nil, fmt.Errorf("constants %s and %s of %s are both named %s", owner, c.Name, t.Name(), refs[c.Name])
+		}
+		owners[refs[c.Name]] = c.Name
+	}
+	return refs, nil
+}
+
+// enumLiteral formats a constant value as a TypeScript literal
+func enumLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		// Strings get quoted
+		return tsString(v)
+	case int64, int, uint64, float64, bool:
+		// Numeric and boolean constants: no quotes
+		return fmt.Sprintf("%v", v)
+	default:
+		// Fallback to string representation, if needed
+		return tsString(fmt.Sprintf("%v", v))
+	}
+}
+
+// hasRecordKeys reports whether every enum value can key a TypeScript Record
+func hasRecordKeys(constants []EnumConstant) bool {
+	for _, c := range constants {
+		switch c.jsonValue().(type) {
+		case string, int64, int, uint64, float64:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// renderQuotedEnum renders the codec of an enum field tagged with the string option of the json tag.
+// encoding/json writes each value as JSON inside a string, e.g. "1" for 1 and "\"open\"" for "open".
+func renderQuotedEnum(constants []EnumConstant) (string, error) {
+	var literals []string
+	for _, c := range uniqueEnumValues(constants) {
+		encoded, err := json.Marshal(c.Value)
+		if err != nil {
+			return "", fmt.Errorf("encoding constant %s: %w", c.Name, err)
+		}
+		literals = append(literals, fmt.Sprintf("t.literal(%s)", tsString(string(encoded))))
+	}
+	if len(literals) == 1 {
+		return literals[0], nil
+	}
+	return fmt.Sprintf("t.union([%s])", strings.Join(literals, ", ")), nil
+}
+
+// tsString quotes a string as a TypeScript string literal
+func tsString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+// isStringEnum reports whether every constant of the enum is a string
+func isStringEnum(constants []EnumConstant) bool {
+	for _, c := range constants {
+		if _, ok := c.jsonValue().(string); !ok {
+			return false
+		}
+	}
+	return len(constants) > 0
+}
+
+// uniqueEnumValues keeps the first constant declared for each value, preserving order
+func uniqueEnumValues(constants []EnumConstant) []EnumConstant {
+	seen := make(map[interface{}]struct{}, len(constants))
+	unique := make([]EnumConstant, 0, len(constants))
+	for _, c := range constants {
+		if _, ok := seen[c.jsonValue()]; ok {
+			continue
+		}
+		seen[c.jsonValue()] = struct{}{}
+		unique = append(unique, c)
+	}
+	return unique
+}
@@ -0,0 +1,410 @@
+package generators_test
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/fixtures"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/generators"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IO-TS:Enum Output", func() {
+	It("should emit t.keyof, a values array and a label map for string enums", func() {
+		type Holder struct {
+			Value fixtures.ExampleString `json:"value"`
+		}
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Enums: generators.EnumOptions{Style: generators.EnumStyleKeyof, Values: true, Labels: true},
+		})
+		result, err := generator.Generate(Holder{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const ExampleStringExampleString1 = "1" as const;
+export const ExampleStringExampleString3 = "3" as const;
+export const ExampleStringExampleStringTwo = "2" as const;
+
+export const ExampleStringC = t.keyof({
+  "1": null,
+  "3": null,
+  "2": null,
+});
+
+export type ExampleString = t.TypeOf<typeof ExampleStringC>;
+
+export const ExampleStringValues = [
+  ExampleStringExampleString1,
+  ExampleStringExampleStringTwo,
+  ExampleStringExampleString3,
+] as const;
+
+export const ExampleStringLabels: Record<ExampleString, string> = {
+  [ExampleStringExampleString1]: "ExampleString1",
+  [ExampleStringExampleStringTwo]: "ExampleStringTwo",
+  [ExampleStringExampleString3]: "ExampleString3",
+};
+
+export const HolderC = t.type({
+  value: ExampleStringC,
+});
+export type Holder = t.TypeOf<typeof HolderC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should select the output per enum type and keep unions for numeric enums", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			EnumOverrides: map[reflect.Type]generators.EnumOptions{
+				reflect.TypeOf(fixtures.ExampleString("")): {Style: generators.EnumStyleKeyof},
+				reflect.TypeOf(fixtures.ExampleInt(0)):     {Style: generators.EnumStyleKeyof, Values: true},
+			},
+		})
+		result, err := generator.Generate(fixtures.Example{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("export const ExampleStringC = t.keyof({"))
+		Expect(result).NotTo(ContainSubstring("ExampleStringValues"))
+		Expect(utils.NormalizeWhitespace(result)).To(ContainSubstring(utils.NormalizeWhitespace(`
+export const ExampleIntC = t.union([
+t.literal(ExampleIntCode1),
+t.literal(ExampleIntCodeTwo)
+]);
+
+export type ExampleInt = t.TypeOf<typeof ExampleIntC>;
+
+export const ExampleIntValues = [
+  ExampleIntCode1,
+  ExampleIntCodeTwo,
+] as const;
+`)))
+	})
+})
+
+var _ = Describe("IO-TS:Enum Naming", func() {
+	type Holder struct {
+		Value fixtures.ExampleString `json:"value"`
+		Code  fixtures.ExampleInt    `json:"code"`
+	}
+
+	generate := func(naming generators.EnumNaming) (string, error) {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Enums: generators.EnumOptions{Naming: naming},
+		})
+		return generator.Generate(Holder{})
+	}
+
+	It("should strip a shared type-name prefix", func() {
+		result, err := generate(generators.EnumNamingStripPrefix)
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(ContainSubstring(utils.NormalizeWhitespace(`
+export const ExampleString1 = "1" as const;
+export const ExampleString3 = "3" as const;
+export const ExampleStringTwo = "2" as const;
+
+export const ExampleStringC = t.union([
+t.literal(ExampleString1),
+t.literal(ExampleString3),
+t.literal(ExampleStringTwo)
+]);
+`)))
+		Expect(result).To(ContainSubstring("export const ExampleIntCode1 = 1 as const;"))
+	})
+
+	It("should keep Go names verbatim", func() {
+		result, err := generate(generators.EnumNamingVerbatim)
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("export const ExampleStringTwo = \"2\" as const;"))
+		Expect(result).To(ContainSubstring("export const Code1 = 1 as const;"))
+		Expect(result).To(ContainSubstring("t.literal(Code1)"))
+	})
+
+	It("should group constants in a namespace object", func() {
+		result, err := generate(generators.EnumNamingNamespace)
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(ContainSubstring(utils.NormalizeWhitespace(`
+export const ExampleString = {
+  ExampleString1: "1",
+  ExampleString3: "3",
+  Two: "2",
+} as const;
+
+export const ExampleStringC = t.union([
+t.literal(ExampleString.ExampleString1),
+t.literal(ExampleString.ExampleString3),
+t.literal(ExampleString.Two)
+]);
+
+export type ExampleString = t.TypeOf<typeof ExampleStringC>;
+`)))
+	})
+
+	It("should report enums whose constants produce the same TypeScript name", func() {
+		generator := generators.NewIoTsGenerator()
+		_, err := generator.Generate(fixtures.Grant{})
+		Expect(err).To(MatchError(ContainSubstring(`TypeScript name "AccessLevelRead" is declared by both`)))
+
+		generator = generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Enums: generators.EnumOptions{Naming: generators.EnumNamingVerbatim},
+		})
+		_, err = generator.Generate(fixtures.Grant{})
+		Expect(err).To(BeNil())
+	})
+
+	It("should report constants of one enum that produce the same TypeScript name", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Enums: generators.EnumOptions{Naming: generators.EnumNamingStripPrefix},
+		})
+		_, err := generator.Generate(fixtures.Membership{})
+		Expect(err).To(MatchError(ContainSubstring("constants TierGold and Gold of Tier are both named TierGold")))
+
+		_, err = generators.NewIoTsGenerator().Generate(fixtures.Membership{})
+		Expect(err).To(BeNil())
+	})
+})
+
+var _ = Describe("IO-TS:Enum Order", func() {
+	It("should sort constants by name by default", func() {
+		result, err := generators.NewIoTsGenerator().Generate(fixtures.Alert{})
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(ContainSubstring(utils.NormalizeWhitespace(`
+export const SeverityC = t.union([
+t.literal(SeverityHigh),
+t.literal(SeverityLow),
+t.literal(SeverityMedium)
+]);
+`)))
+	})
+
+	It("should keep the source declaration order when requested", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Enums: generators.EnumOptions{Order: generators.EnumOrderDeclaration},
+		})
+		result, err := generator.Generate(fixtures.Alert{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const SeverityLow = 0 as const;
+export const SeverityMedium = 1 as const;
+export const SeverityHigh = 2 as const;
+
+export const SeverityC = t.union([
+t.literal(SeverityLow),
+t.literal(SeverityMedium),
+t.literal(SeverityHigh)
+]);
+
+export type Severity = t.TypeOf<typeof SeverityC>;
+
+export const AlertC = t.type({
+  severity: SeverityC,
+});
+export type Alert = t.TypeOf<typeof AlertC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+})
+
+var _ = Describe("IO-TS:Enum Flags", func() {
+	It("should emit a codec accepting any combination of flags declared with shifts", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Enums: generators.EnumOptions{Order: generators.EnumOrderDeclaration},
+		})
+		result, err := generator.Generate(fixtures.FileMode{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const PermissionPermissionRead = 1 as const;
+export const PermissionPermissionWrite = 2 as const;
+export const PermissionPermissionExec = 4 as const;
+export const PermissionPermissionAll = 7 as const;
+
+export const PermissionMask = 7;
+
+export const PermissionC = new t.Type<number, number, unknown>(
+  "Permission",
+  (u): u is number => typeof u === "number" && Number.isInteger(u) && u >= 0 && (u & ~PermissionMask) === 0,
+  (u, c) => (PermissionC.is(u) ? t.success(u) : t.failure(u, c)),
+  t.identity
+);
+
+export type Permission = t.TypeOf<typeof PermissionC>;
+
+export const hasPermission = (value: Permission, flag: Permission): boolean => (value & flag) === flag;
+
+export const combinePermission = (...flags: Permission[]): Permission => flags.reduce((acc, flag) => acc | flag, 0);
+
+export const FileModeC = t.type({
+  permission: PermissionC,
+});
+export type FileMode = t.TypeOf<typeof FileModeC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should mark the constants declared with shifts", func() {
+		constants, err := generators.NewPackageCache().EnumConstants(reflect.TypeOf(fixtures.Permission(0)))
+
+		Expect(err).To(BeNil())
+		Expect(constants).To(HaveLen(4))
+		Expect(constants[0].Flag).To(BeTrue())
+		Expect(constants[2].Flag).To(BeTrue())
+		Expect(constants[3].Flag).To(BeFalse())
+	})
+
+	It("should keep unions for iota enums without shifts", func() {
+		text, err := generators.GetIoTsEnumText(reflect.TypeOf(fixtures.Severity(0)))
+
+		Expect(err).To(BeNil())
+		Expect(text).To(ContainSubstring("t.union(["))
+		Expect(text).NotTo(ContainSubstring("SeverityMask"))
+	})
+
+	It("should honour the Flags option per enum type", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			EnumOverrides: map[reflect.Type]generators.EnumOptions{
+				reflect.TypeOf(fixtures.Permission(0)): {Flags: generators.EnumFlagsNever},
+				reflect.TypeOf(fixtures.ExampleInt(0)): {Flags: generators.EnumFlagsAlways},
+			},
+		})
+		result, err := generator.GenerateAll(fixtures.FileMode{}, fixtures.Example{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("export const PermissionC = t.union(["))
+		Expect(result).NotTo(ContainSubstring("hasPermission"))
+		Expect(result).To(ContainSubstring("export const ExampleIntMask = 3;"))
+		Expect(result).To(ContainSubstring("export const combineExampleInt = "))
+		Expect(result).To(ContainSubstring("export const ExampleStringC = t.union(["))
+	})
+})
+
+var _ = Describe("IO-TS:Enum Labels", func() {
+	It("should label values with the comments of their constants", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Enums: generators.EnumOptions{Order: generators.EnumOrderDeclaration, Labels: true, LabelSource: generators.EnumLabelComment},
+		})
+		result, err := generator.Generate(fixtures.Account{})
+
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(ContainSubstring(utils.NormalizeWhitespace(`
+export const AccountStatusLabels: Record<AccountStatus, string> = {
+  [AccountStatusAccountActive]: "Active account",
+  [AccountStatusAccountSuspended]: "Suspended by an administrator",
+  [AccountStatusAccountClosed]: "AccountClosed",
+};
+`)))
+	})
+
+	It("should label values with their String method", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Enums: generators.EnumOptions{Order: generators.EnumOrderDeclaration, Labels: true, LabelSource: generators.EnumLabelString},
+		})
+		result, err := generator.Generate(fixtures.Account{})
+
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(ContainSubstring(utils.NormalizeWhitespace(`
+export const AccountStatusLabels: Record<AccountStatus, string> = {
+  [AccountStatusAccountActive]: "Active",
+  [AccountStatusAccountSuspended]: "Suspended",
+  [AccountStatusAccountClosed]: "Closed",
+};
+`)))
+	})
+
+	It("should fall back to constant names for types without a String method", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Enums: generators.EnumOptions{Labels: true, LabelSource: generators.EnumLabelString},
+		})
+		result, err := generator.Generate(fixtures.Alert{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring(`[SeverityLow]: "Low",`))
+	})
+
+	It("should skip labels for boolean enums with a warning", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Enums: generators.EnumOptions{Labels: true},
+		})
+		result, diagnostics, err := generator.GenerateWithDiagnostics(fixtures.Switch{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("export const ToggleC = t.union(["))
+		Expect(result).NotTo(ContainSubstring("ToggleLabels"))
+		Expect(diagnostics).To(ConsistOf(And(
+			HaveField("Severity", generators.SeverityWarning),
+			HaveField("TypePath", "github.com/VictorMarcolino/golang-struct-to-io-ts/fixtures.Toggle"),
+			HaveField("Message", "labels skipped: enum values are not strings or numbers"),
+		)))
+	})
+})
+
+var _ = Describe("IO-TS:Enum Marshalers", func() {
+	It("should emit the values produced by MarshalText and MarshalJSON", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Enums: generators.EnumOptions{Order: generators.EnumOrderDeclaration, Style: generators.EnumStyleKeyof},
+		})
+		result, err := generator.Generate(fixtures.Palette{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const ColorColorRed = "red" as const;
+export const ColorColorGreen = "green" as const;
+export const ColorColorBlue = "blue" as const;
+
+export const ColorC = t.keyof({
+  "red": null,
+  "green": null,
+  "blue": null,
+});
+
+export type Color = t.TypeOf<typeof ColorC>;
+
+export const ShadeShadeLight = 10 as const;
+export const ShadeShadeDark = 20 as const;
+
+export const ShadeC = t.union([
+t.literal(ShadeShadeLight),
+t.literal(ShadeShadeDark)
+]);
+
+export type Shade = t.TypeOf<typeof ShadeC>;
+
+export const PaletteC = t.type({
+  color: ColorC,
+  shade: ShadeC,
+});
+export type Palette = t.TypeOf<typeof PaletteC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should keep the Go values in the constants returned by the cache", func() {
+		values, err := generators.GetEnumConstantsAsMap(reflect.TypeOf(fixtures.Color(0)))
+
+		Expect(err).To(BeNil())
+		Expect(values).To(HaveKeyWithValue("ColorGreen", int64(1)))
+	})
+
+	It("should report marshalers that fail", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{})
+		_, err := generator.Generate(fixtures.Diary{})
+
+		var generateErr *generators.GenerateError
+		Expect(errors.As(err, &generateErr)).To(BeTrue())
+		Expect(generateErr.Errors()).To(HaveLen(1))
+		Expect(generateErr.Errors()[0].Error()).To(ContainSubstring("mood cannot be serialized"))
+
+		_, err = generators.GetIoTsEnumText(reflect.TypeOf(fixtures.Mood(0)))
+		Expect(err).To(MatchError(ContainSubstring("marshaling constant MoodUnknown")))
+	})
+})
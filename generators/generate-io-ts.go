@@ -2,13 +2,89 @@ package generators
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strings"
 )
 
 // TypeScriptGeneratorOptions defines options for generating TypeScript interfaces
 type TypeScriptGeneratorOptions struct {
 	TreatArraysAsOptional bool
+	// PackageCache answers enum and source directive lookups; generators share a default cache when nil
+	PackageCache *PackageCache
+	// Strict turns every fallback to t.unknown into an error
+	Strict bool
+	// AllowUnknown lists field paths (e.g. "Order.Metadata") that may fall back to t.unknown in strict mode.
+	// An entry also allows everything nested below that field.
+	AllowUnknown []string
+	// Ordering selects how independent declarations are ordered in the output
+	Ordering Ordering
+	// Enums configures the output of every enum type
+	Enums EnumOptions
+	// EnumOverrides replaces Enums for specific enum types
+	EnumOverrides map[reflect.Type]EnumOptions
+	// Int64Policy selects the codec of int64 and uint64 fields tagged with the string option
+	Int64Policy Int64Policy
+	// Integers selects the codec of Go integer kinds; floats are always t.number
+	Integers IntegerPolicy
+	// TagKeys lists the struct tags that name fields, in order of preference, e.g. json then yaml.
+	// A field is named by the first tag it carries and skipped when it carries none. Defaults to json.
+	TagKeys []string
+	// HoistAnonymousStructs declares anonymous structs as named codecs instead of repeating them inline.
+	// They are named after the enclosing type and field path, e.g. OrderShippingAddress, or with iots:"name=...".
+	HoistAnonymousStructs bool
+	// EmbedAsIntersection emits embedded and inline named structs as t.intersection([BaseC, t.type({...})])
+	// instead of copying their fields, so the base codec is shared by every embedding type
+	EmbedAsIntersection bool
+	// Readonly emits t.readonly for structs and records and t.readonlyArray for slices, so the types
+	// derived with t.TypeOf are deeply readonly. Fields can opt in or out with iots:"readonly" and iots:"mutable";
+	// the per-field tag stops at references to named struct codecs, whose own fields stay mutable.
+	Readonly bool
+	// FieldNaming names the fields whose tag gives no name. When set, exported untagged fields
+	// are generated instead of skipped, e.g. with FieldNameCamelCase or a function of your own.
+	FieldNaming FieldNamer
+}
+
+// IntegerPolicy selects how Go integer kinds are validated
+type IntegerPolicy int
+
+const (
+	// IntegerAsNumber emits t.number, accepting fractional values
+	IntegerAsNumber IntegerPolicy = iota
+	// IntegerAsInt emits the t.Int branded codec
+	IntegerAsInt
+	// IntegerAsRange emits t.Int branded with the range of the Go kind, e.g. Uint8C for 0..255.
+	// int and int64 have no range that fits in a JavaScript number and use t.Int.
+	IntegerAsRange
+)
+
+// Int64Policy selects how 64-bit integers sent as JSON strings are decoded.
+// JSON numbers are parsed as float64 by JavaScript, so 64-bit integers only keep
+// their precision when the Go field is tagged with the string option.
+type Int64Policy int
+
+const (
+	// Int64AsNumber decodes them with NumberFromString, losing precision above 2^53
+	Int64AsNumber Int64Policy = iota
+	// Int64AsString keeps them as t.string
+	Int64AsString
+	// Int64AsBigInt decodes them with BigIntFromString from io-ts-types
+	Int64AsBigInt
+)
+
+// clone returns a copy of the options that shares no slices or maps with the original
+func (o TypeScriptGeneratorOptions) clone() TypeScriptGeneratorOptions {
+	o.AllowUnknown = append([]string(nil), o.AllowUnknown...)
+	o.TagKeys = append([]string(nil), o.TagKeys...)
+	if o.EnumOverrides != nil {
+		overrides := make(map[reflect.Type]EnumOptions, len(o.EnumOverrides))
+		for t, enumOptions := range o.EnumOverrides {
+			overrides[t] = enumOptions
+		}
+		o.EnumOverrides = overrides
+	}
+	return o
 }
 
 // TypeConverter defines an interface for converting Go types to io-ts types
@@ -24,23 +100,42 @@ type FieldProcessor interface {
 
 // CodeBuilder handles the assembly of generated code
 type CodeBuilder struct {
-	imports         []string
-	typeDefinitions []string
-	processedTypes  map[string]struct{}
+	imports []string
+	// namedImports holds the names imported from each module other than io-ts
+	namedImports   map[string][]string
+	declarations   []Declaration
+	processedTypes map[string]struct{}
+	ordering       Ordering
 }
 
 // NewCodeBuilder creates a new CodeBuilder instance
 func NewCodeBuilder() *CodeBuilder {
 	return &CodeBuilder{
-		imports:         []string{"import * as t from 'io-ts';\n\n"},
-		typeDefinitions: []string{},
-		processedTypes:  make(map[string]struct{}),
+		imports:        []string{"import * as t from 'io-ts';"},
+		namedImports:   make(map[string][]string),
+		declarations:   []Declaration{},
+		processedTypes: make(map[string]struct{}),
 	}
 }
 
-// AddTypeDefinition adds a type definition to the builder
+// AddTypeDefinition adds a type definition without known dependencies to the builder
 func (cb *CodeBuilder) AddTypeDefinition(typeDef string) {
-	cb.typeDefinitions = append(cb.typeDefinitions, typeDef)
+	cb.AddDeclaration(Declaration{Code: typeDef})
+}
+
+// AddDeclaration adds a declaration to the builder
+func (cb *CodeBuilder) AddDeclaration(declaration Declaration) {
+	cb.declarations = append(cb.declarations, declaration)
+}
+
+// AddImport imports a name from a module, e.g. NumberFromString from io-ts-types
+func (cb *CodeBuilder) AddImport(module, name string) {
+	for _, existing := range cb.namedImports[module] {
+		if existing == name {
+			return
+		}
+	}
+	cb.namedImports[module] = append(cb.namedImports[module], name)
 }
 
 // IsTypeProcessed checks if a type has already been processed
@@ -54,36 +149,66 @@ func (cb *CodeBuilder) MarkTypeProcessed(typeKey string) {
 	cb.processedTypes[typeKey] = struct{}{}
 }
 
-// Build assembles the final code output
+// Build assembles the final code output, declaring every codec before its first use
 func (cb *CodeBuilder) Build() string {
 	var sb strings.Builder
 	for _, imp := range cb.imports {
-		sb.WriteString(imp)
+		sb.WriteString(imp + "\n")
+	}
+	modules := make([]string, 0, len(cb.namedImports))
+	for module := range cb.namedImports {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	for _, module := range modules {
+		names := append([]string(nil), cb.namedImports[module]...)
+		sort.Strings(names)
+		sb.WriteString(fmt.Sprintf("import { %s } from '%s';\n", strings.Join(names, ", "), module))
 	}
-	for _, typeDef := range cb.typeDefinitions {
-		sb.WriteString(typeDef)
+	sb.WriteString("\n")
+	for _, declaration := range orderDeclarations(cb.declarations, cb.ordering) {
+		sb.WriteString(declaration.Code)
 	}
 	return sb.String()
 }
 
 // DefaultTypeConverter is the default implementation of TypeConverter
 type DefaultTypeConverter struct {
-	generator *IoTsGenerator
+	session *Session
 }
 
 // Convert converts a Go type to its corresponding io-ts type
 func (tc *DefaultTypeConverter) Convert(goType reflect.Type, isOptional bool) string {
 	goType = dereferenceType(goType)
 
+	if tc.session.namedRoots[goType] && goType != tc.session.declaring {
+		tc.session.processNamedType(goType)
+		tc.session.require(getTypeKey(goType))
+		return wrapOptional(tc.session.namedRootRef(goType), isOptional)
+	}
+
 	// Special case for map[string]interface{}
 	if goType.Kind() == reflect.Map && goType.Key().Kind() == reflect.String && goType.Elem().Kind() == reflect.Interface {
-		ioTsType := "t.record(t.string, t.unknown)"
+		tc.session.diagnostics.pushPath("{}")
+		valueIoTsType := tc.session.unknownType(goType.Elem(), "interface value")
+		tc.session.diagnostics.popPath()
+		ioTsType := tc.session.readonlyCodec(fmt.Sprintf("t.record(t.string, %s)", valueIoTsType))
 		return wrapOptional(ioTsType, isOptional)
 	}
 
 	var ioTsType string
-	if IsEnumType(goType) {
-		tc.generator.generateEnumType(goType)
+	if isCollectionKind(goType.Kind()) && goType.Name() != "" {
+		// A named slice or map containing itself, e.g. type Tree map[string]Tree, has no finite codec
+		if tc.session.converting[goType] {
+			tc.session.diagnostics.report(SeverityError, goType, nil, "recursive %s types are not supported", goType.Kind())
+			return wrapOptional("t.unknown", isOptional)
+		}
+		tc.session.converting[goType] = true
+		defer delete(tc.session.converting, goType)
+	}
+	if tc.session.isEnumType(goType) {
+		tc.session.generateEnumType(goType)
+		tc.session.require(getTypeKey(goType))
 		ioTsType = fmt.Sprintf("%sC", goType.Name())
 		return wrapOptional(ioTsType, isOptional)
 	}
@@ -94,7 +219,11 @@ func (tc *DefaultTypeConverter) Convert(goType reflect.Type, isOptional bool) st
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 		reflect.Float32, reflect.Float64:
-		ioTsType = "t.number"
+		if is64BitInteger(goType) {
+			tc.session.diagnostics.report(SeverityWarning, goType, nil,
+				"64-bit integer emitted as t.number loses precision above 2^53; tag the field with the string option")
+		}
+		ioTsType = tc.session.numberCodec(goType)
 		break
 	case reflect.Bool:
 		ioTsType = "t.boolean"
@@ -103,130 +232,268 @@ func (tc *DefaultTypeConverter) Convert(goType reflect.Type, isOptional bool) st
 		elementType := goType.Elem()
 		// Check if the element is a pointer
 		isElementOptional := elementType.Kind() == reflect.Ptr
+		tc.session.diagnostics.pushPath("[]")
 		elementIoTsType := tc.Convert(elementType, isElementOptional)
-		ioTsType = fmt.Sprintf("t.array(%s)", elementIoTsType)
+		tc.session.diagnostics.popPath()
+		ioTsType = tc.session.arrayCodec(elementIoTsType)
 		break
 	case reflect.Struct:
-		typeName := goType.Name()
-		if typeName == "" {
+		typeName := tc.session.structName(goType)
+		if typeName == "" && !tc.session.options.HoistAnonymousStructs {
 			// Anonymous struct, generate inline type
-			inlineType := tc.generator.generateInlineStruct(goType)
-			ioTsType = inlineType
+			ioTsType = tc.session.generateInlineStruct(goType)
+			break
+		}
+		if typeName == "" {
+			ioTsType = tc.session.hoistStruct(goType)
 		} else {
-			tc.generator.processStruct(goType)
+			tc.session.processStruct(goType)
+			tc.session.require(getTypeKey(goType))
 			ioTsType = fmt.Sprintf("%sC", typeName)
 		}
+		if tc.session.readonly && !tc.session.options.Readonly {
+			// The declaration follows the global option; a readonly field wraps the reference
+			ioTsType = fmt.Sprintf("t.readonly(%s)", ioTsType)
+		}
 		break
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		ioTsType = tc.session.unsupportedKind(goType)
+	case reflect.Interface:
+		ioTsType = tc.session.unknownType(goType, "interface")
+	case reflect.Map:
+		if goType.Key().Kind() != reflect.String {
+			ioTsType = tc.session.unknownType(goType, "unsupported map")
+			break
+		}
+		tc.session.diagnostics.pushPath("{}")
+		valueIoTsType := tc.Convert(goType.Elem(), goType.Elem().Kind() == reflect.Ptr)
+		tc.session.diagnostics.popPath()
+		ioTsType = tc.session.readonlyCodec(fmt.Sprintf("t.record(t.string, %s)", valueIoTsType))
 	default:
-		ioTsType = "t.unknown"
+		ioTsType = tc.session.unknownType(goType, "unsupported kind "+goType.Kind().String())
 	}
 
 	return wrapOptional(ioTsType, isOptional)
 }
 
-// IoTsGenerator encapsulates the logic to generate io-ts types
+// IoTsGenerator encapsulates the logic to generate io-ts types.
+// Its options are fixed at construction and every run works on its own Session,
+// so a single generator can be shared by concurrent callers.
 type IoTsGenerator struct {
-	options       TypeScriptGeneratorOptions
-	typeConverter TypeConverter
-	codeBuilder   *CodeBuilder
+	options TypeScriptGeneratorOptions
 }
 
 // NewIoTsGenerator creates a new instance of NewIoTsGenerator with the provided options
 func NewIoTsGenerator(options ...TypeScriptGeneratorOptions) *IoTsGenerator {
 	chosenOptions := TypeScriptGeneratorOptions{}
 	if len(options) != 0 {
-		chosenOptions = options[0]
+		chosenOptions = options[0].clone()
 	}
-	generator := &IoTsGenerator{
-		options:     chosenOptions,
-		codeBuilder: NewCodeBuilder(),
+	if chosenOptions.PackageCache == nil {
+		chosenOptions.PackageCache = defaultPackageCache
 	}
-	generator.typeConverter = &DefaultTypeConverter{generator: generator}
-	return generator
+	return &IoTsGenerator{options: chosenOptions}
 }
 
-// Generate takes any struct and generates its corresponding io-ts type
+// Generate takes any struct and generates its corresponding io-ts type.
+// Every call produces an independent module; when errors are found it returns a *GenerateError listing them.
 func (g *IoTsGenerator) Generate(inputStruct interface{}) (string, error) {
-	t := reflect.TypeOf(inputStruct)
-	t = dereferenceType(t)
+	result, _, err := g.GenerateWithDiagnostics(inputStruct)
+	return result, err
+}
 
-	if t.Kind() != reflect.Struct {
-		return "", fmt.Errorf("input is not a struct")
-	}
+// GenerateWithDiagnostics is Generate that also returns the diagnostics of the run, warnings included
+func (g *IoTsGenerator) GenerateWithDiagnostics(inputStruct interface{}) (string, []Diagnostic, error) {
+	return g.GenerateAllWithDiagnostics(inputStruct)
+}
+
+// GenerateAll generates a single module for all the given roots, declaring every shared type once
+func (g *IoTsGenerator) GenerateAll(roots ...interface{}) (string, error) {
+	result, _, err := g.GenerateAllWithDiagnostics(roots...)
+	return result, err
+}
+
+// GenerateAllWithDiagnostics is GenerateAll that also returns the diagnostics of the run, warnings included
+func (g *IoTsGenerator) GenerateAllWithDiagnostics(roots ...interface{}) (string, []Diagnostic, error) {
+	return g.run(func(session *Session) {
+		session.Add(roots...)
+	})
+}
+
+// GenerateNamed generates the io-ts types of a root under the given name, e.g. for anonymous structs
+// or to rename a type in the output. References to the root use the new name too.
+func (g *IoTsGenerator) GenerateNamed(name string, root interface{}) (string, error) {
+	result, _, err := g.GenerateNamedWithDiagnostics(name, root)
+	return result, err
+}
 
-	g.processStruct(t)
-	return g.codeBuilder.Build(), nil
+// GenerateNamedWithDiagnostics is GenerateNamed that also returns the diagnostics of the run, warnings included
+func (g *IoTsGenerator) GenerateNamedWithDiagnostics(name string, root interface{}) (string, []Diagnostic, error) {
+	return g.run(func(session *Session) {
+		session.AddNamed(name, root)
+	})
+}
+
+// run generates the roots added by add in a new session
+func (g *IoTsGenerator) run(add func(session *Session)) (string, []Diagnostic, error) {
+	session := g.NewSession()
+	add(session)
+	result, err := session.Build()
+	return result, session.Diagnostics(), err
 }
 
 // processStruct processes a struct and generates its io-ts type
-func (g *IoTsGenerator) processStruct(t reflect.Type) {
+func (s *Session) processStruct(t reflect.Type) {
 	t = dereferenceType(t)
 
 	typeKey := getTypeKey(t)
-	if g.codeBuilder.IsTypeProcessed(typeKey) || t.Name() == "" {
+	if s.codeBuilder.IsTypeProcessed(typeKey) || s.structName(t) == "" {
+		return
+	}
+	if s.processing[typeKey] {
+		// Only fields of the struct itself can use Self; A -> B -> A has no declaration order
+		s.diagnostics.report(SeverityError, t, nil, "%s reaches itself through another type, which is not supported", s.structName(t))
+		return
+	}
+	s.processing[typeKey] = true
+	defer delete(s.processing, typeKey)
+
+	s.checkIdentifier(t)
+	s.declareNames(t, []string{s.structName(t) + "C"}, s.structName(t))
+	s.enterStruct(t)
+	defer s.exitStruct()
+	// Declarations are shared, so they follow the global option whatever field first reached them
+	defer s.setReadonly(s.options.Readonly)()
+	s.processNestedStructs(t)
+	s.codeBuilder.MarkTypeProcessed(typeKey)
+	s.enterDeclaration(typeKey)
+	typeDef := s.generateIoTsType(t)
+	s.codeBuilder.AddDeclaration(Declaration{
+		Key:      typeKey,
+		Name:     s.structName(t),
+		PkgPath:  t.PkgPath(),
+		Code:     typeDef,
+		Requires: s.exitDeclaration(),
+	})
+}
+
+// processNamedType declares the codec of a root that is not a struct, e.g. type Users []User
+func (s *Session) processNamedType(t reflect.Type) {
+	typeKey := getTypeKey(t)
+	if s.codeBuilder.IsTypeProcessed(typeKey) {
 		return
 	}
+	name := s.structName(t)
+	s.checkIdentifier(t)
+	s.declareNames(t, []string{name + "C"}, name)
+	s.codeBuilder.MarkTypeProcessed(typeKey)
+	// Declarations are shared, so they follow the global option whatever field first reached them
+	defer s.setReadonly(s.options.Readonly)()
+	previous := s.declaring
+	s.declaring = t
+	s.enterDeclaration(typeKey)
+	s.enterStruct(t)
+	codec := s.typeConverter.Convert(t, false)
+	s.exitStruct()
+	s.declaring = previous
+	code := fmt.Sprintf("export const %sC = %s;\n", name, codec)
+	code += fmt.Sprintf("export type %s = t.TypeOf<typeof %sC>;\n\n", name, name)
+	s.codeBuilder.AddDeclaration(Declaration{
+		Key:      typeKey,
+		Name:     name,
+		PkgPath:  t.PkgPath(),
+		Code:     code,
+		Requires: s.exitDeclaration(),
+	})
+}
 
-	g.processNestedStructs(t)
-	g.codeBuilder.MarkTypeProcessed(typeKey)
-	typeDef := g.generateIoTsType(t)
-	g.codeBuilder.AddTypeDefinition(typeDef)
+// namedRootRef returns a reference to the codec of a named root, wrapped in t.readonly for readonly fields
+func (s *Session) namedRootRef(t reflect.Type) string {
+	ref := s.structName(t) + "C"
+	if s.readonly && !s.options.Readonly {
+		ref = fmt.Sprintf("t.readonly(%s)", ref)
+	}
+	return ref
 }
 
 // processNestedStructs processes nested structs within a parent struct
-func (g *IoTsGenerator) processNestedStructs(t reflect.Type) {
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		if g.shouldSkipField(field) {
+func (s *Session) processNestedStructs(t reflect.Type) {
+	for _, field := range s.structFields(t) {
+		if s.shouldSkipField(field) || parseIotsTag(field).codec != "" {
 			continue
 		}
+		s.diagnostics.pushPath(field.Name)
+		s.processNestedField(t, field)
+		s.diagnostics.popPath()
+	}
+}
 
-		fieldType := dereferenceType(field.Type)
+// processNestedField processes the struct types reachable from a single field of the parent struct
+func (s *Session) processNestedField(t reflect.Type, field reflect.StructField) {
+	fieldType := dereferenceType(field.Type)
 
-		// Avoid infinite recursion: skip processing if the field type is the same as the parent type
-		if getTypeKey(fieldType) == getTypeKey(t) {
-			continue
-		}
+	// Avoid infinite recursion: skip processing if the field type is the same as the parent type
+	if getTypeKey(fieldType) == getTypeKey(t) {
+		return
+	}
+	if name := parseIotsTag(field).name; name != "" {
+		s.hoistName = name
+		defer func() { s.hoistName = "" }()
+	}
 
-		if isStructType(fieldType) {
-			if strings.Contains(field.Tag.Get("json"), ",inline") {
-				g.processNestedStructs(fieldType)
-			} else if fieldType.Name() == "" {
+	if isStructType(fieldType) {
+		if s.isFlattened(field) {
+			s.processNestedStructs(fieldType)
+		} else if s.structName(fieldType) == "" {
+			// Anonymous struct
+			s.typeConverter.Convert(fieldType, s.isFieldOptional(field))
+		} else {
+			s.processStruct(fieldType)
+		}
+	} else if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array {
+		elementType := dereferenceType(fieldType.Elem())
+		// Avoid infinite recursion for slices/arrays of the same type
+		if getTypeKey(elementType) == getTypeKey(t) {
+			return
+		}
+		if isStructType(elementType) {
+			s.diagnostics.pushPath("[]")
+			if s.structName(elementType) == "" {
 				// Anonymous struct
-				g.typeConverter.Convert(fieldType, g.isFieldOptional(field))
+				s.typeConverter.Convert(elementType, false)
 			} else {
-				g.processStruct(fieldType)
-			}
-		} else if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array {
-			elementType := dereferenceType(fieldType.Elem())
-			// Avoid infinite recursion for slices/arrays of the same type
-			if getTypeKey(elementType) == getTypeKey(t) {
-				continue
-			}
-			if isStructType(elementType) {
-				if elementType.Name() == "" {
-					// Anonymous struct
-					g.typeConverter.Convert(elementType, false)
-				} else {
-					g.processStruct(elementType)
-				}
+				s.processStruct(elementType)
 			}
+			s.diagnostics.popPath()
 		}
 	}
 }
 
 // generateIoTsType generates the io-ts type for a struct and returns it as a string
-func (g *IoTsGenerator) generateIoTsType(t reflect.Type) string {
+func (s *Session) generateIoTsType(t reflect.Type) string {
+	name := s.structName(t)
 	// If the struct is recursive (contains a field of its own type), emit a t.recursion wrapper
 	if isRecursiveStruct(t) {
-		var fieldLines []string
-		for i := 0; i < t.NumField(); i++ {
-			field := t.Field(i)
-			if g.shouldSkipField(field) {
+		var fieldLines, embeds []string
+		for _, field := range s.structFields(t) {
+			if s.shouldSkipField(field) {
+				continue
+			}
+			if codec, ok := s.embeddedCodec(field); ok {
+				embeds = append(embeds, codec)
+				continue
+			}
+			tag := s.parseTag(field)
+			jsonFieldName := tag.name
+
+			// A codec chosen with the iots tag replaces the Self detection
+			if parseIotsTag(field).codec != "" {
+				s.diagnostics.pushPath(field.Name)
+				ioTsType := s.fieldCodec(field, tag, s.isOptional(field, tag))
+				s.diagnostics.popPath()
+				fieldLines = append(fieldLines, fmt.Sprintf("      %s: %s,", formatPropertyName(jsonFieldName), ioTsType))
 				continue
 			}
-			jsonTag := field.Tag.Get("json")
-			jsonFieldName := strings.Split(jsonTag, ",")[0]
 
 			// Work with the raw type to preserve pointer/collection info for Self detection
 			rawType := field.Type
@@ -244,100 +511,215 @@ func (g *IoTsGenerator) generateIoTsType(t reflect.Type) string {
 				elDeref := dereferenceType(el)
 				if el.Kind() == reflect.Ptr && getTypeKey(elDeref) == selfKey {
 					// []*Self -> t.array(t.union([Self, t.undefined]))
-					fieldLines = append(fieldLines, fmt.Sprintf("      %s: t.array(t.union([Self, t.undefined])),", formatPropertyName(jsonFieldName)))
+					restore := s.enterFieldReadonly(field)
+					fieldLines = append(fieldLines, fmt.Sprintf("      %s: %s,", formatPropertyName(jsonFieldName), s.arrayCodec("t.union([Self, t.undefined])")))
+					restore()
 					continue
 				}
 				if getTypeKey(elDeref) == selfKey {
 					// []Self -> t.array(Self)
-					fieldLines = append(fieldLines, fmt.Sprintf("      %s: t.array(Self),", formatPropertyName(jsonFieldName)))
+					restore := s.enterFieldReadonly(field)
+					fieldLines = append(fieldLines, fmt.Sprintf("      %s: %s,", formatPropertyName(jsonFieldName), s.arrayCodec("Self")))
+					restore()
 					continue
 				}
 			}
 
+			// String keyed maps whose values reference self
+			if rawType.Kind() == reflect.Map && rawType.Key().Kind() == reflect.String && getTypeKey(dereferenceType(rawType.Elem())) == selfKey {
+				value := "Self"
+				if rawType.Elem().Kind() == reflect.Ptr {
+					value = "t.union([Self, t.undefined])"
+				}
+				restore := s.enterFieldReadonly(field)
+				fieldLines = append(fieldLines, fmt.Sprintf("      %s: %s,", formatPropertyName(jsonFieldName), s.readonlyCodec(fmt.Sprintf("t.record(t.string, %s)", value))))
+				restore()
+				continue
+			}
+
 			// Inline fields are not expected to be self in recursion test, but handle generically
-			if strings.Contains(jsonTag, ",inline") {
-				inlineFields := g.processInlineField(field)
+			if s.isFlattened(field) {
+				inlineFields := s.processInlineField(field)
 				for _, f := range inlineFields {
 					fieldLines = append(fieldLines, "      "+strings.TrimSpace(f))
 				}
 				continue
 			}
 			// Use normal conversion for other fields
-			isOptional := strings.Contains(jsonTag, ",omitempty") || g.isFieldOptional(field)
-			ioTsType := g.typeConverter.Convert(field.Type, isOptional)
+			isOptional := s.isOptional(field, tag)
+			s.diagnostics.pushPath(field.Name)
+			ioTsType := s.fieldCodec(field, tag, isOptional)
+			s.diagnostics.popPath()
 			fieldLines = append(fieldLines, fmt.Sprintf("      %s: %s,", formatPropertyName(jsonFieldName), ioTsType))
 		}
 
 		// Build the recursion block
-		typeDef := fmt.Sprintf("export const %sC = t.recursion(\n  '%s',\n  Self =>\n    t.type({\n%s\n    }),\n);\n\n", t.Name(), t.Name(), strings.Join(fieldLines, "\n"))
-		typeDef += fmt.Sprintf("export type %s = t.TypeOf<typeof %sC>;\n", t.Name(), t.Name())
+		codec := fmt.Sprintf("t.type({\n%s\n    })", strings.Join(fieldLines, "\n"))
+		if len(embeds) > 0 {
+			codec = intersectCodecs(embeds, fieldLines)
+		}
+		codec = s.readonlyCodec(codec)
+		typeDef := fmt.Sprintf("export const %sC = t.recursion(\n  '%s',\n  Self =>\n    %s,\n);\n\n", name, name, codec)
+		typeDef += fmt.Sprintf("export type %s = t.TypeOf<typeof %sC>;\n", name, name)
 		return typeDef
 	}
 
 	// Non-recursive: default behavior
-	var fields []string
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		if g.shouldSkipField(field) {
+	var fields, embeds []string
+	for _, field := range s.structFields(t) {
+		if s.shouldSkipField(field) {
 			continue
 		}
-		if strings.Contains(field.Tag.Get("json"), ",inline") {
-			inlineFields := g.processInlineField(field)
+		if codec, ok := s.embeddedCodec(field); ok {
+			embeds = append(embeds, codec)
+		} else if s.isFlattened(field) {
+			inlineFields := s.processInlineField(field)
 			fields = append(fields, inlineFields...)
 		} else {
-			fieldDef := g.processField(field)
+			fieldDef := s.processField(field)
 			fields = append(fields, fieldDef)
 		}
 	}
 
-	typeDef := fmt.Sprintf("export const %sC = t.type({\n%s\n});\n", t.Name(), strings.Join(fields, "\n"))
-	typeDef += fmt.Sprintf("export type %s = t.TypeOf<typeof %sC>;\n\n", t.Name(), t.Name())
+	typeDef := fmt.Sprintf("export const %sC = %s;\n", name, s.readonlyCodec(intersectCodecs(embeds, fields)))
+	typeDef += fmt.Sprintf("export type %s = t.TypeOf<typeof %sC>;\n\n", name, name)
 	return typeDef
 }
 
-// processField processes a single field and returns its definition
-func (g *IoTsGenerator) processField(field reflect.StructField) string {
-	jsonTag := field.Tag.Get("json")
-	jsonFieldName := strings.Split(jsonTag, ",")[0]
+// embeddedCodec returns the codec of an embedded or inline named struct when EmbedAsIntersection is set.
+// encoding/json flattens embedded structs unless their tag gives them a name.
+func (s *Session) embeddedCodec(field reflect.StructField) (string, bool) {
+	fieldType := dereferenceType(field.Type)
+	if !s.options.EmbedAsIntersection || fieldType.Kind() != reflect.Struct || s.structName(fieldType) == "" {
+		return "", false
+	}
+	tag := s.parseTag(field)
+	if !tag.inline && !(field.Anonymous && !tag.explicit) {
+		return "", false
+	}
+	s.diagnostics.pushPath(field.Name)
+	s.processStruct(fieldType)
+	s.diagnostics.popPath()
+	s.require(getTypeKey(fieldType))
+	return s.structName(fieldType) + "C", true
+}
 
-	isOptional := strings.Contains(jsonTag, ",omitempty") || g.isFieldOptional(field)
+// intersectCodecs combines the codecs of embedded structs with a t.type of the own fields.
+// The t.type is left out when there are embedded structs but no own fields.
+func intersectCodecs(embeds []string, fields []string) string {
+	own := fmt.Sprintf("t.type({\n%s\n})", strings.Join(fields, "\n"))
+	if len(embeds) == 0 {
+		return own
+	}
+	members := embeds
+	if len(fields) > 0 {
+		members = append(members, own)
+	}
+	if len(members) == 1 {
+		return members[0]
+	}
+	return fmt.Sprintf("t.intersection([%s])", strings.Join(members, ", "))
+}
 
-	fieldType := dereferenceType(field.Type)
-	if IsEnumType(fieldType) {
-		// Ensure the enum is generated if it hasn't been yet
-		g.generateEnumType(fieldType)
+// processField processes a single field and returns its definition
+func (s *Session) processField(field reflect.StructField) string {
+	s.diagnostics.pushPath(field.Name)
+	defer s.diagnostics.popPath()
+
+	tag := s.parseTag(field)
+	ioTsType := s.fieldCodec(field, tag, s.isOptional(field, tag))
 
-		// Reference the generated union type, e.g. `ValidateSeverityC`
-		ioTsType := fmt.Sprintf("%sC", fieldType.Name())
+	return fmt.Sprintf("  %s: %s,", formatPropertyName(tag.name), ioTsType)
+}
 
-		// If optional, wrap in union with t.undefined
-		if isOptional {
-			ioTsType = fmt.Sprintf("t.union([%s, t.undefined])", ioTsType)
+// fieldCodec returns the codec of a field, honouring its iots tag and the string option of its json tag
+func (s *Session) fieldCodec(field reflect.StructField, tag fieldTag, isOptional bool) string {
+	override := parseIotsTag(field)
+	for _, option := range override.unknown {
+		s.diagnostics.report(SeverityWarning, field.Type, nil, "unknown iots tag option %q", option)
+	}
+	for _, imp := range override.imports {
+		s.codeBuilder.AddImport(imp.module, imp.name)
+	}
+	if override.codec != "" {
+		return wrapOptional(override.codec, isOptional)
+	}
+	defer s.enterFieldReadonly(field)()
+	if override.name != "" {
+		s.hoistName = override.name
+		defer func() { s.hoistName = "" }()
+	}
+	if tag.asString {
+		if ioTsType, ok := s.stringEncodedCodec(dereferenceType(field.Type)); ok {
+			return wrapOptional(ioTsType, isOptional)
 		}
-		return fmt.Sprintf("  %s: %s,", jsonFieldName, ioTsType)
 	}
+	return s.typeConverter.Convert(field.Type, isOptional)
+}
 
-	// Otherwise, use the normal conversion logic
-	ioTsType := g.typeConverter.Convert(field.Type, isOptional)
-
-	return fmt.Sprintf("  %s: %s,", formatPropertyName(jsonFieldName), ioTsType)
+// stringEncodedCodec returns the codec of a number, boolean or enum encoded as a JSON string.
+// encoding/json ignores the string option on other kinds and on types with a marshaler, and so does
+// the generator. Strings are quoted twice, e.g. "\"abc\"", and t.string accepts them undecoded.
+func (s *Session) stringEncodedCodec(t reflect.Type) (string, bool) {
+	if hasMarshaler(t) {
+		return "", false
+	}
+	if s.isEnumType(t) {
+		constants, _ := s.options.PackageCache.EnumConstants(t)
+		codec, err := renderQuotedEnum(constants)
+		if err != nil {
+			s.diagnostics.report(SeverityError, t, err, "cannot compute the quoted values of the enum")
+			return "t.unknown", true
+		}
+		return codec, true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if is64BitInteger(t) {
+			switch s.options.Int64Policy {
+			case Int64AsString:
+				return "t.string", true
+			case Int64AsBigInt:
+				s.codeBuilder.AddImport("io-ts-types", "BigIntFromString")
+				return "BigIntFromString", true
+			}
+			s.diagnostics.report(SeverityWarning, t, nil,
+				"64-bit integer decoded with NumberFromString loses precision above 2^53; set Int64Policy to keep it")
+		}
+		if s.options.Integers != IntegerAsNumber && isInteger(t) {
+			s.codeBuilder.AddImport("io-ts-types", "IntFromString")
+			return "IntFromString", true
+		}
+		s.codeBuilder.AddImport("io-ts-types", "NumberFromString")
+		return "NumberFromString", true
+	case reflect.Bool:
+		s.codeBuilder.AddImport("io-ts-types", "BooleanFromString")
+		return "BooleanFromString", true
+	case reflect.String:
+		return "t.string", true
+	}
+	return "", false
 }
 
 // processInlineField processes an inlined field and returns its fields
-func (g *IoTsGenerator) processInlineField(field reflect.StructField) []string {
+func (s *Session) processInlineField(field reflect.StructField) []string {
+	s.diagnostics.pushPath(field.Name)
+	defer s.diagnostics.popPath()
+
 	fieldType := dereferenceType(field.Type)
 	var fields []string
 
-	for i := 0; i < fieldType.NumField(); i++ {
-		inlineField := fieldType.Field(i)
-		if g.shouldSkipField(inlineField) {
+	for _, inlineField := range s.structFields(fieldType) {
+		if s.shouldSkipField(inlineField) {
 			continue
 		}
-		if strings.Contains(inlineField.Tag.Get("json"), ",inline") {
-			inlineFields := g.processInlineField(inlineField)
+		if s.isFlattened(inlineField) {
+			inlineFields := s.processInlineField(inlineField)
 			fields = append(fields, inlineFields...)
 		} else {
-			fieldDef := g.processField(inlineField)
+			fieldDef := s.processField(inlineField)
 			fields = append(fields, fieldDef)
 		}
 	}
@@ -346,60 +728,151 @@ func (g *IoTsGenerator) processInlineField(field reflect.StructField) []string {
 }
 
 // generateInlineStruct generates an inline type for anonymous structs
-func (g *IoTsGenerator) generateInlineStruct(t reflect.Type) string {
-	fields := g.generateInlineStructFields(t)
-	return fmt.Sprintf("t.type({\n%s\n})", strings.Join(fields, "\n"))
+func (s *Session) generateInlineStruct(t reflect.Type) string {
+	fields, embeds := s.generateInlineStructFields(t)
+	return s.readonlyCodec(intersectCodecs(embeds, fields))
 }
 
-// generateInlineStructFields collects field definitions from a struct, including embedded fields
-func (g *IoTsGenerator) generateInlineStructFields(t reflect.Type) []string {
-	var fields []string
+// generateInlineStructFields collects field definitions from a struct, including embedded fields,
+// and the codecs of the embedded structs kept as intersections
+func (s *Session) generateInlineStructFields(t reflect.Type) ([]string, []string) {
+	var fields, embeds []string
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		if g.shouldSkipField(field) {
+	for _, field := range s.structFields(t) {
+		if s.shouldSkipField(field) {
 			continue
 		}
 
-		if field.Anonymous {
+		if codec, ok := s.embeddedCodec(field); ok {
+			embeds = append(embeds, codec)
+		} else if field.Anonymous {
 			// Embedded field, include its fields recursively
 			embeddedType := dereferenceType(field.Type)
 			if isStructType(embeddedType) {
-				embeddedFields := g.generateInlineStructFields(embeddedType)
+				s.diagnostics.pushPath(field.Name)
+				embeddedFields, embeddedCodecs := s.generateInlineStructFields(embeddedType)
+				s.diagnostics.popPath()
 				fields = append(fields, embeddedFields...)
+				embeds = append(embeds, embeddedCodecs...)
 			} else {
 				// Not a struct, treat as a regular field
-				fieldDef := g.processField(field)
+				fieldDef := s.processField(field)
 				fields = append(fields, fieldDef)
 			}
 		} else {
-			fieldDef := g.processField(field)
+			fieldDef := s.processField(field)
 			fields = append(fields, fieldDef)
 		}
 	}
 
-	return fields
+	return fields, embeds
+}
+
+// parseTag parses the tag that names a field according to the TagKeys option
+func (s *Session) parseTag(field reflect.StructField) fieldTag {
+	tag := parseFieldTag(field, s.options.TagKeys)
+	if s.options.FieldNaming == nil || tag.explicit {
+		return tag
+	}
+	if tag.untagged {
+		if !field.IsExported() {
+			return tag
+		}
+		tag.skip = false
+	}
+	if !tag.skip {
+		tag.name = s.options.FieldNaming(field.Name)
+	}
+	return tag
+}
+
+// isOptional reports whether a field may be missing, from its iots tag, its json tag or its type
+func (s *Session) isOptional(field reflect.StructField, tag fieldTag) bool {
+	override := parseIotsTag(field)
+	if override.optional || override.required {
+		return override.optional
+	}
+	return tag.omitEmpty || s.isFieldOptional(field)
 }
 
 // isFieldOptional determines if a field should be optional in io-ts
-func (g *IoTsGenerator) isFieldOptional(field reflect.StructField) bool {
+func (s *Session) isFieldOptional(field reflect.StructField) bool {
 	fieldType := field.Type
 
-	if (fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array) && g.options.TreatArraysAsOptional {
+	if (fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array) && s.options.TreatArraysAsOptional {
 		return true
 	}
 
 	return fieldType.Kind() == reflect.Ptr
 }
 
+// structFields returns the fields of a struct with the //iots:ignore and //iots:optional
+// directives of its source folded into their `iots` tags
+func (s *Session) structFields(t reflect.Type) []reflect.StructField {
+	directives, err := s.options.PackageCache.fieldDirectives(t)
+	if err != nil {
+		s.reportUnloadedPackage(t, err)
+	}
+	fields := make([]reflect.StructField, t.NumField())
+	for i := range fields {
+		fields[i] = t.Field(i)
+		if d, ok := directives[fields[i].Name]; ok {
+			fields[i] = withDirectives(fields[i], d)
+		}
+	}
+	return fields
+}
+
+// isFlattened reports whether the fields of a struct field are merged into its parent, as encoding/json
+// does for inline fields and for embedded structs whose tag gives no name
+func (s *Session) isFlattened(field reflect.StructField) bool {
+	tag := s.parseTag(field)
+	return tag.inline || field.Anonymous && !tag.explicit && isStructType(dereferenceType(field.Type))
+}
+
 // shouldSkipField determines if a field should be skipped
-func (g *IoTsGenerator) shouldSkipField(field reflect.StructField) bool {
+func (s *Session) shouldSkipField(field reflect.StructField) bool {
+	if parseIotsTag(field).skip {
+		return true
+	}
 	// Always include anonymous fields (embedded structs)
 	if field.Anonymous {
 		return false
 	}
-	jsonTag := field.Tag.Get("json")
-	return jsonTag == "" || jsonTag == "-"
+	return s.parseTag(field).skip
+}
+
+// enterFieldReadonly applies the readonly or mutable option of the field's iots tag and returns
+// a function restoring the previous state
+func (s *Session) enterFieldReadonly(field reflect.StructField) func() {
+	override := parseIotsTag(field)
+	if override.readonly || override.mutable {
+		return s.setReadonly(override.readonly)
+	}
+	return func() {}
+}
+
+// setReadonly switches readonly output on or off and returns a function restoring the previous state
+func (s *Session) setReadonly(readonly bool) func() {
+	previous := s.readonly
+	s.readonly = readonly
+	return func() { s.readonly = previous }
+}
+
+// arrayCodec returns the array codec of an element codec, readonly when readonly output is on
+func (s *Session) arrayCodec(element string) string {
+	if s.readonly {
+		return fmt.Sprintf("t.readonlyArray(%s)", element)
+	}
+	return fmt.Sprintf("t.array(%s)", element)
+}
+
+// readonlyCodec wraps a struct or record codec in t.readonly when readonly output is on
+func (s *Session) readonlyCodec(codec string) string {
+	if s.readonly {
+		return fmt.Sprintf("t.readonly(%s)", codec)
+	}
+	return codec
 }
 
 // Helper functions
@@ -412,6 +885,10 @@ func wrapOptional(ioTsType string, isOptional bool) string {
 }
 
 func getTypeKey(t reflect.Type) string {
+	if t.Name() == "" {
+		// Identical unnamed types are the same reflect.Type and have the same string form
+		return t.String()
+	}
 	return t.PkgPath() + "." + t.Name()
 }
 
@@ -428,26 +905,105 @@ func formatPropertyName(name string) string {
 	if name == "" {
 		return "''"
 	}
-	isIdent := true
+	if isIdentifier(name) {
+		return name
+	}
+	escaped := strings.ReplaceAll(name, "\\", "\\\\")
+	escaped = strings.ReplaceAll(escaped, "'", "\\'")
+	return fmt.Sprintf("'%s'", escaped)
+}
+
+// isIdentifier reports whether name is a valid TypeScript identifier
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
 	for i, r := range name {
 		if i == 0 {
 			if !((r == '_') || (r == '$') || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z')) {
-				isIdent = false
-				break
+				return false
 			}
 		} else {
 			if !((r == '_') || (r == '$') || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')) {
-				isIdent = false
-				break
+				return false
 			}
 		}
 	}
-	if isIdent {
-		return name
+	return true
+}
+
+// integerRange describes a Go integer kind whose values fit in a JavaScript number
+type integerRange struct {
+	name   string
+	goType reflect.Type
+	// min and max bound the values; max is empty when only the sign is checked
+	min, max string
+}
+
+// integerRanges lists the range codecs emitted by IntegerAsRange
+var integerRanges = map[reflect.Kind]integerRange{
+	reflect.Int8:    {"Int8", reflect.TypeOf(int8(0)), "-128", "127"},
+	reflect.Int16:   {"Int16", reflect.TypeOf(int16(0)), "-32768", "32767"},
+	reflect.Int32:   {"Int32", reflect.TypeOf(int32(0)), "-2147483648", "2147483647"},
+	reflect.Uint8:   {"Uint8", reflect.TypeOf(uint8(0)), "0", "255"},
+	reflect.Uint16:  {"Uint16", reflect.TypeOf(uint16(0)), "0", "65535"},
+	reflect.Uint32:  {"Uint32", reflect.TypeOf(uint32(0)), "0", "4294967295"},
+	reflect.Uint:    {"Uint", reflect.TypeOf(uint(0)), "0", ""},
+	reflect.Uint64:  {"Uint64", reflect.TypeOf(uint64(0)), "0", ""},
+	reflect.Uintptr: {"Uintptr", reflect.TypeOf(uintptr(0)), "0", ""},
+}
+
+// numberCodec returns the codec of a numeric kind according to the Integers option
+func (s *Session) numberCodec(t reflect.Type) string {
+	if !isInteger(t) || s.options.Integers == IntegerAsNumber {
+		return "t.number"
 	}
-	escaped := strings.ReplaceAll(name, "\\", "\\\\")
-	escaped = strings.ReplaceAll(escaped, "'", "\\'")
-	return fmt.Sprintf("'%s'", escaped)
+	r, ok := integerRanges[t.Kind()]
+	if s.options.Integers == IntegerAsInt || !ok {
+		return "t.Int"
+	}
+	key := "range:" + r.name
+	if !s.codeBuilder.IsTypeProcessed(key) {
+		s.codeBuilder.MarkTypeProcessed(key)
+		s.declareNames(r.goType, []string{r.name + "C"}, r.name)
+		s.declareNames(r.goType, nil, r.name+"Brand")
+		s.codeBuilder.AddDeclaration(Declaration{Key: key, Name: r.name, Code: renderIntegerRange(r)})
+	}
+	s.require(key)
+	return r.name + "C"
+}
+
+// renderIntegerRange renders the branded codec of an integer range
+func renderIntegerRange(r integerRange) string {
+	condition := fmt.Sprintf("n >= %s", r.min)
+	if r.max != "" {
+		condition += fmt.Sprintf(" && n <= %s", r.max)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("export interface %sBrand {\n  readonly %s: unique symbol;\n}\n\n", r.name, r.name))
+	sb.WriteString(fmt.Sprintf("export const %sC = t.brand(\n  t.Int,\n  (n): n is t.Branded<t.Int, %sBrand> => %s,\n  '%s'\n);\n\n", r.name, r.name, condition, r.name))
+	sb.WriteString(fmt.Sprintf("export type %s = t.TypeOf<typeof %sC>;\n\n", r.name, r.name))
+	return sb.String()
+}
+
+// isInteger reports whether the type is a Go integer kind
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// is64BitInteger reports whether values of the type may not fit in a JavaScript number
+func is64BitInteger(t reflect.Type) bool {
+	return t.Kind() == reflect.Int64 || t.Kind() == reflect.Uint64
+}
+
+// isCollectionKind reports whether values of the kind contain other values: slices, arrays and maps
+func isCollectionKind(kind reflect.Kind) bool {
+	return kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
 }
 
 func isStructType(t reflect.Type) bool {
@@ -455,31 +1011,144 @@ func isStructType(t reflect.Type) bool {
 	return t.Kind() == reflect.Struct
 }
 
-// isRecursiveStruct checks whether the struct has a field that refers to its own type (directly or via pointer)
+// isRecursiveStruct checks whether the struct has a field that refers to its own type, directly, via pointer
+// or as the element of a slice or map
 func isRecursiveStruct(t reflect.Type) bool {
 	for i := 0; i < t.NumField(); i++ {
 		fieldType := dereferenceType(t.Field(i).Type)
 		if getTypeKey(fieldType) == getTypeKey(t) {
 			return true
 		}
-		// Check slices/arrays of the same type
-		if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array {
-			elem := dereferenceType(fieldType.Elem())
-			if getTypeKey(elem) == getTypeKey(t) {
-				return true
-			}
+		// Check slices/arrays and string keyed maps of the same type
+		isCollection := fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array ||
+			(fieldType.Kind() == reflect.Map && fieldType.Key().Kind() == reflect.String)
+		if isCollection && getTypeKey(dereferenceType(fieldType.Elem())) == getTypeKey(t) {
+			return true
 		}
 	}
 	return false
 }
 
 // generateEnumType generates the io-ts type for an enum and adds it to the builder.
-func (g *IoTsGenerator) generateEnumType(t reflect.Type) {
-	if g.codeBuilder.IsTypeProcessed(getTypeKey(t)) {
+func (s *Session) generateEnumType(t reflect.Type) {
+	if s.codeBuilder.IsTypeProcessed(getTypeKey(t)) {
+		return
+	}
+	s.checkIdentifier(t)
+	constants, _ := s.options.PackageCache.EnumConstants(t)
+	if marshaled, err := marshalEnumConstants(t, constants); err != nil {
+		s.diagnostics.report(SeverityError, t, err, "cannot compute the JSON values of the enum")
+	} else {
+		constants = marshaled
+	}
+	options := s.enumOptions(t)
+	if isFlagEnum(constants, options.Flags) && flagMask(constants) > math.MaxInt32 {
+		// JavaScript bitwise operators work on 32-bit signed integers
+		s.diagnostics.report(SeverityWarning, t, nil, "flags above bit 30 are not supported by TypeScript bitwise operators")
+	}
+	if options.Labels && !hasRecordKeys(constants) {
+		// TypeScript records are keyed by strings or numbers
+		s.diagnostics.report(SeverityWarning, t, nil, "labels skipped: enum values are not strings or numbers")
+		options.Labels = false
+	}
+	iotsText, names, err := renderEnum(t, constants, options)
+	if err != nil {
+		s.diagnostics.report(SeverityError, t, nil, "%s", err)
 		return
 	}
-	// For some reason code is not reaching this point
-	iotsText := GetIoTsEnumText(t)
-	g.codeBuilder.AddTypeDefinition(iotsText)
-	g.codeBuilder.MarkTypeProcessed(getTypeKey(t))
+	s.declareNames(t, names, t.Name())
+	s.codeBuilder.AddDeclaration(Declaration{
+		Key:     getTypeKey(t),
+		Name:    t.Name(),
+		PkgPath: t.PkgPath(),
+		Code:    iotsText,
+	})
+	s.codeBuilder.MarkTypeProcessed(getTypeKey(t))
+}
+
+// enumOptions returns the options of an enum type, applying its override if any
+func (s *Session) enumOptions(t reflect.Type) EnumOptions {
+	if enumOptions, ok := s.options.EnumOverrides[t]; ok {
+		return enumOptions
+	}
+	return s.options.Enums
+}
+
+// isEnumType checks if the given type has constants declared in its package
+func (s *Session) isEnumType(t reflect.Type) bool {
+	constants, err := s.options.PackageCache.EnumConstants(t)
+	if err != nil {
+		s.reportUnloadedPackage(t, err)
+	}
+	return len(constants) > 0
+}
+
+// reportUnloadedPackage warns once per package that its source could not be loaded, so its enums
+// and //iots: directives are not seen
+func (s *Session) reportUnloadedPackage(t reflect.Type, err error) {
+	s.diagnostics.reportOnce(t.PkgPath(), SeverityWarning, t, err, "enum detection and source directives skipped")
+}
+
+// unknownType returns t.unknown, reporting an error in strict mode unless the current field path is allowed
+func (s *Session) unknownType(t reflect.Type, reason string) string {
+	if s.options.Strict && !s.isUnknownAllowed(s.diagnostics.fieldPath()) {
+		s.diagnostics.report(SeverityError, t, nil, "%s falls back to t.unknown in strict mode", reason)
+	}
+	return "t.unknown"
+}
+
+// unsupportedKind returns t.unknown for a kind encoding/json cannot marshal, e.g. chan or func.
+// Strict mode treats it like any other t.unknown fallback; otherwise it is reported as a warning.
+func (s *Session) unsupportedKind(t reflect.Type) string {
+	if s.options.Strict {
+		return s.unknownType(t, "unsupported kind "+t.Kind().String())
+	}
+	s.diagnostics.report(SeverityWarning, t, nil, "unsupported kind %s", t.Kind())
+	return "t.unknown"
+}
+
+// isUnknownAllowed checks the field path and its parents against the AllowUnknown list
+func (s *Session) isUnknownAllowed(fieldPath string) bool {
+	for _, allowed := range s.options.AllowUnknown {
+		if fieldPath == allowed {
+			return true
+		}
+		if strings.HasPrefix(fieldPath, allowed) && strings.ContainsAny(fieldPath[len(allowed):len(allowed)+1], ".[{") {
+			return true
+		}
+	}
+	return false
+}
+
+// declareNames registers the top-level value names and the type name declared for a Go type,
+// reporting an error when another Go type already declared the same TypeScript name
+func (s *Session) declareNames(t reflect.Type, values []string, typeName string) {
+	owner := typePath(t)
+	register := func(key, name string) {
+		if existing, ok := s.declaredNames[key]; ok && existing != owner {
+			s.diagnostics.report(SeverityError, t, nil, "TypeScript name %q is declared by both %s and %s", name, existing, owner)
+			return
+		}
+		s.declaredNames[key] = owner
+	}
+	for _, name := range values {
+		register(name, name)
+	}
+	// Types live in their own TypeScript declaration space
+	register("type "+typeName, typeName)
+}
+
+// structName returns the name a struct is declared under: the name given to its root, or its Go name
+func (s *Session) structName(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	return t.Name()
+}
+
+// checkIdentifier reports an error when a type name cannot be used as a TypeScript identifier
+func (s *Session) checkIdentifier(t reflect.Type) {
+	if !isIdentifier(s.structName(t)) {
+		s.diagnostics.report(SeverityError, t, nil, "type name %q is not a valid TypeScript identifier", s.structName(t))
+	}
 }
@@ -1,6 +1,9 @@
 package generators_test
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/VictorMarcolino/golang-struct-to-io-ts/fixtures"
 	"github.com/VictorMarcolino/golang-struct-to-io-ts/generators"
 	"github.com/VictorMarcolino/golang-struct-to-io-ts/utils"
@@ -887,3 +890,799 @@ export type AtExample = t.TypeOf<typeof AtExampleC>;
 		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
 	})
 })
+
+var _ = Describe("IO-TS:Strict Mode", func() {
+	type Event struct {
+		Name     string                 `json:"name"`
+		Payload  interface{}            `json:"payload"`
+		Metadata map[string]interface{} `json:"metadata"`
+		Counts   map[int]string         `json:"counts"`
+	}
+
+	It("should list every field that falls back to t.unknown", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{Strict: true})
+		_, err := generator.Generate(Event{})
+
+		var generateErr *generators.GenerateError
+		Expect(errors.As(err, &generateErr)).To(BeTrue())
+		var paths []string
+		for _, d := range generateErr.Errors() {
+			paths = append(paths, d.FieldPath)
+		}
+		Expect(paths).To(Equal([]string{"Event.Payload", "Event.Metadata{}", "Event.Counts"}))
+	})
+
+	It("should allow fields that are intentionally untyped", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Strict:       true,
+			AllowUnknown: []string{"Event.Payload", "Event.Metadata", "Event.Counts"},
+		})
+		result, err := generator.Generate(Event{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const EventC = t.type({
+  name: t.string,
+  payload: t.unknown,
+  metadata: t.record(t.string, t.unknown),
+  counts: t.unknown,
+});
+export type Event = t.TypeOf<typeof EventC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should allow funcs, channels and complex numbers listed in AllowUnknown", func() {
+		type WithChan struct {
+			Cb      func()     `json:"cb"`
+			Updates chan int   `json:"updates"`
+			Phase   complex128 `json:"phase"`
+		}
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			Strict:       true,
+			AllowUnknown: []string{"WithChan.Cb", "WithChan.Updates"},
+		})
+		_, err := generator.Generate(WithChan{})
+
+		var generateErr *generators.GenerateError
+		Expect(errors.As(err, &generateErr)).To(BeTrue())
+		Expect(generateErr.Errors()).To(HaveLen(1))
+		Expect(generateErr.Errors()[0].FieldPath).To(Equal("WithChan.Phase"))
+		Expect(generateErr.Errors()[0].Message).To(Equal("unsupported kind complex128 falls back to t.unknown in strict mode"))
+	})
+
+	It("should keep emitting t.unknown when strict mode is off", func() {
+		generator := generators.NewIoTsGenerator()
+		_, err := generator.Generate(Event{})
+		Expect(err).To(BeNil())
+	})
+})
+
+var _ = Describe("IO-TS:JSON Tag Options", func() {
+	It("should decode numbers and booleans tagged with the string option from strings", func() {
+		generator := generators.NewIoTsGenerator()
+		result, diagnostics, err := generator.GenerateWithDiagnostics(fixtures.Invoice{})
+
+		expected := `
+import * as t from 'io-ts';
+import { BooleanFromString, NumberFromString } from 'io-ts-types';
+
+export const InvoiceC = t.type({
+  id: NumberFromString,
+  paid: BooleanFromString,
+  total: t.union([NumberFromString, t.undefined]),
+  number: t.string,
+  lines: t.array(t.number),
+  severity: t.union([t.literal("0"), t.literal("1"), t.literal("2")]),
+  Currency: t.union([t.string, t.undefined]),
+});
+export type Invoice = t.TypeOf<typeof InvoiceC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+		Expect(diagnostics).To(ConsistOf(
+			HaveField("FieldPath", "Invoice.ID"),
+		))
+	})
+
+	It("should decode quoted string enums and ignore the string option of enums with a marshaler", func() {
+		type Quoted struct {
+			Status fixtures.AccountStatus `json:"status,string"`
+			Color  fixtures.Color         `json:"color,string"`
+		}
+		result, err := generators.NewIoTsGenerator().Generate(Quoted{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring(`status: t.union([t.literal("\"active\""), t.literal("\"suspended\""), t.literal("\"closed\"")]),`))
+		Expect(result).To(ContainSubstring("color: ColorC,"))
+		Expect(result).NotTo(ContainSubstring("export const AccountStatusC"))
+	})
+
+	It("should not import io-ts-types when no field needs it", func() {
+		result, err := generators.NewIoTsGenerator().Generate(fixtures.Alert{})
+
+		Expect(err).To(BeNil())
+		Expect(result).NotTo(ContainSubstring("io-ts-types"))
+	})
+})
+
+var _ = Describe("IO-TS:64-bit Integers", func() {
+	type Snowflake struct {
+		ID      uint64   `json:"id,string"`
+		Parent  *int64   `json:"parent,string,omitempty"`
+		Count   int64    `json:"count"`
+		Related []uint64 `json:"related"`
+		Small   int32    `json:"small,string"`
+	}
+
+	It("should decode 64-bit integers tagged with the string option according to the policy", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{Int64Policy: generators.Int64AsBigInt})
+		result, err := generator.Generate(Snowflake{})
+
+		expected := `
+import * as t from 'io-ts';
+import { BigIntFromString, NumberFromString } from 'io-ts-types';
+
+export const SnowflakeC = t.type({
+  id: BigIntFromString,
+  parent: t.union([BigIntFromString, t.undefined]),
+  count: t.number,
+  related: t.array(t.number),
+  small: NumberFromString,
+});
+export type Snowflake = t.TypeOf<typeof SnowflakeC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should keep 64-bit integers as strings", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{Int64Policy: generators.Int64AsString})
+		result, err := generator.Generate(Snowflake{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("id: t.string,"))
+		Expect(result).To(ContainSubstring("parent: t.union([t.string, t.undefined]),"))
+		Expect(result).NotTo(ContainSubstring("BigIntFromString"))
+	})
+
+	It("should warn about every 64-bit integer emitted as a plain number", func() {
+		generator := generators.NewIoTsGenerator()
+		result, diagnostics, err := generator.GenerateWithDiagnostics(Snowflake{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("id: NumberFromString,"))
+		Expect(diagnostics).To(ConsistOf(
+			unloadedPackage,
+			HaveField("FieldPath", "Snowflake.ID"),
+			HaveField("FieldPath", "Snowflake.Parent"),
+			HaveField("FieldPath", "Snowflake.Count"),
+			HaveField("FieldPath", "Snowflake.Related[]"),
+		))
+		for _, diagnostic := range diagnostics[1:] {
+			Expect(diagnostic.Severity).To(Equal(generators.SeverityWarning))
+			Expect(diagnostic.Message).To(ContainSubstring("loses precision above 2^53"))
+		}
+	})
+})
+
+var _ = Describe("IO-TS:Integer Codecs", func() {
+	type Pixel struct {
+		Red     uint8    `json:"red"`
+		Offset  int16    `json:"offset"`
+		Count   int      `json:"count"`
+		Size    *uint    `json:"size"`
+		Weights []uint8  `json:"weights"`
+		Ratio   float64  `json:"ratio"`
+		Layer   int32    `json:"layer,string"`
+		Alpha   *float32 `json:"alpha,omitempty"`
+	}
+
+	It("should emit t.Int for integer kinds and keep t.number for floats", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{Integers: generators.IntegerAsInt})
+		result, err := generator.Generate(Pixel{})
+
+		expected := `
+import * as t from 'io-ts';
+import { IntFromString } from 'io-ts-types';
+
+export const PixelC = t.type({
+  red: t.Int,
+  offset: t.Int,
+  count: t.Int,
+  size: t.union([t.Int, t.undefined]),
+  weights: t.array(t.Int),
+  ratio: t.number,
+  layer: IntFromString,
+  alpha: t.union([t.number, t.undefined]),
+});
+export type Pixel = t.TypeOf<typeof PixelC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should emit each range codec once before its first use", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{Integers: generators.IntegerAsRange})
+		result, diagnostics, err := generator.GenerateWithDiagnostics(Pixel{})
+
+		expected := `
+import * as t from 'io-ts';
+import { IntFromString } from 'io-ts-types';
+
+export interface Uint8Brand {
+  readonly Uint8: unique symbol;
+}
+
+export const Uint8C = t.brand(
+  t.Int,
+  (n): n is t.Branded<t.Int, Uint8Brand> => n >= 0 && n <= 255,
+  'Uint8'
+);
+
+export type Uint8 = t.TypeOf<typeof Uint8C>;
+
+export interface Int16Brand {
+  readonly Int16: unique symbol;
+}
+
+export const Int16C = t.brand(
+  t.Int,
+  (n): n is t.Branded<t.Int, Int16Brand> => n >= -32768 && n <= 32767,
+  'Int16'
+);
+
+export type Int16 = t.TypeOf<typeof Int16C>;
+
+export interface UintBrand {
+  readonly Uint: unique symbol;
+}
+
+export const UintC = t.brand(
+  t.Int,
+  (n): n is t.Branded<t.Int, UintBrand> => n >= 0,
+  'Uint'
+);
+
+export type Uint = t.TypeOf<typeof UintC>;
+
+export const PixelC = t.type({
+  red: Uint8C,
+  offset: Int16C,
+  count: t.Int,
+  size: t.union([UintC, t.undefined]),
+  weights: t.array(Uint8C),
+  ratio: t.number,
+  layer: IntFromString,
+  alpha: t.union([t.number, t.undefined]),
+});
+export type Pixel = t.TypeOf<typeof PixelC>;
+`
+		Expect(err).To(BeNil())
+		Expect(diagnostics).To(ConsistOf(unloadedPackage))
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should report range codecs clashing with generated types", func() {
+		type Uint8 struct {
+			Value uint8 `json:"value"`
+		}
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{Integers: generators.IntegerAsRange})
+		_, err := generator.Generate(Uint8{})
+
+		Expect(err).To(MatchError(ContainSubstring(`TypeScript name "Uint8C" is declared by both`)))
+	})
+})
+
+var _ = Describe("IO-TS:iots Tag", func() {
+	type Timestamp struct {
+		Seconds int `json:"seconds"`
+	}
+	type Audit struct {
+		Secret    string     `json:"secret" iots:"-"`
+		CreatedAt Timestamp  `json:"createdAt" iots:"type=DateFromISOString,import=io-ts-types"`
+		Tags      []string   `json:"tags" iots:"type=t.array(NonEmptyString, 'Tags'),import=io-ts-types:NonEmptyString"`
+		DeletedAt *Timestamp `json:"deletedAt" iots:"type=DateFromISOString,import=io-ts-types,required"`
+		Note      string     `json:"note" iots:"optional"`
+		Author    *string    `json:"author,omitempty" iots:"required"`
+	}
+
+	It("should override codecs, imports and optionality and exclude fields", func() {
+		generator := generators.NewIoTsGenerator()
+		result, diagnostics, err := generator.GenerateWithDiagnostics(Audit{})
+
+		expected := `
+import * as t from 'io-ts';
+import { DateFromISOString, NonEmptyString } from 'io-ts-types';
+
+export const AuditC = t.type({
+  createdAt: DateFromISOString,
+  tags: t.array(NonEmptyString, 'Tags'),
+  deletedAt: DateFromISOString,
+  note: t.union([t.string, t.undefined]),
+  author: t.string,
+});
+export type Audit = t.TypeOf<typeof AuditC>;
+`
+		Expect(err).To(BeNil())
+		Expect(diagnostics).To(ConsistOf(unloadedPackage))
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should override codecs of recursive structs", func() {
+		type Node struct {
+			Children []Node `json:"children" iots:"type=t.array(t.unknown)"`
+			Parent   *Node  `json:"parent"`
+		}
+		result, err := generators.NewIoTsGenerator().Generate(Node{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("children: t.array(t.unknown),"))
+		Expect(result).To(ContainSubstring("parent: t.union([Self, t.undefined]),"))
+	})
+
+	It("should warn about options it cannot parse", func() {
+		type Bad struct {
+			Value string `json:"value" iots:"codec=X,type=t.array(UUID),import=io-ts-types"`
+		}
+		generator := generators.NewIoTsGenerator()
+		result, diagnostics, err := generator.GenerateWithDiagnostics(Bad{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("value: t.array(UUID),"))
+		Expect(diagnostics).To(ConsistOf(
+			unloadedPackage,
+			HaveField("Message", `unknown iots tag option "codec=X"`),
+			HaveField("Message", `unknown iots tag option "import=io-ts-types"`),
+		))
+	})
+})
+
+var _ = Describe("IO-TS:Tag Keys", func() {
+	type Base struct {
+		Kind string `bson:"kind" mapstructure:"kind"`
+	}
+	type Document struct {
+		ID      string `bson:"_id" json:"id"`
+		Title   string `yaml:"title,omitempty" json:"heading"`
+		Body    string `yaml:"body"`
+		Draft   bool   `bson:"-" json:"draft"`
+		Version int    `json:"version,string"`
+		Base    `bson:",inline" mapstructure:",squash"`
+	}
+
+	It("should name fields with the first tag found among the tag keys", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{TagKeys: []string{"bson", "yaml"}})
+		result, err := generator.Generate(Document{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const DocumentC = t.type({
+  _id: t.string,
+  title: t.union([t.string, t.undefined]),
+  body: t.string,
+  kind: t.string,
+});
+export type Document = t.TypeOf<typeof DocumentC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should fall back to later tag keys and only honour the string option of json tags", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{TagKeys: []string{"mapstructure", "json"}})
+		result, err := generator.Generate(Document{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("heading: t.string,"))
+		Expect(result).To(ContainSubstring("version: NumberFromString,"))
+		Expect(result).To(ContainSubstring("kind: t.string,"))
+	})
+
+	It("should default to the json tag", func() {
+		result, err := generators.NewIoTsGenerator().Generate(Document{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("id: t.string,"))
+		Expect(result).NotTo(ContainSubstring("body"))
+	})
+})
+
+var _ = Describe("IO-TS:Field Naming", func() {
+	type Profile struct {
+		UserID      string `json:"id"`
+		HTTPServer  string
+		AvatarURL2  *string
+		DisplayName string `json:",omitempty"`
+		Ignored     string `json:"-"`
+		internal    string
+	}
+
+	DescribeTable("should name fields whose tag gives no name",
+		func(naming generators.FieldNamer, expected string) {
+			generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{FieldNaming: naming})
+			result, err := generator.Generate(Profile{internal: "x"})
+
+			Expect(err).To(BeNil())
+			Expect(utils.NormalizeWhitespace(result)).To(ContainSubstring(utils.NormalizeWhitespace(expected)))
+		},
+		Entry("Go names", generators.FieldNamer(generators.FieldNameGo), `
+export const ProfileC = t.type({
+  id: t.string,
+  HTTPServer: t.string,
+  AvatarURL2: t.union([t.string, t.undefined]),
+  DisplayName: t.union([t.string, t.undefined]),
+});`),
+		Entry("camelCase", generators.FieldNamer(generators.FieldNameCamelCase), `
+export const ProfileC = t.type({
+  id: t.string,
+  httpServer: t.string,
+  avatarUrl2: t.union([t.string, t.undefined]),
+  displayName: t.union([t.string, t.undefined]),
+});`),
+		Entry("snake_case", generators.FieldNamer(generators.FieldNameSnakeCase), `
+export const ProfileC = t.type({
+  id: t.string,
+  http_server: t.string,
+  avatar_url2: t.union([t.string, t.undefined]),
+  display_name: t.union([t.string, t.undefined]),
+});`),
+		Entry("kebab-case", generators.FieldNamer(generators.FieldNameKebabCase), `
+export const ProfileC = t.type({
+  id: t.string,
+  'http-server': t.string,
+  'avatar-url2': t.union([t.string, t.undefined]),
+  'display-name': t.union([t.string, t.undefined]),
+});`),
+		Entry("a function of your own", generators.FieldNamer(func(name string) string { return "x" + name }), `
+export const ProfileC = t.type({
+  id: t.string,
+  xHTTPServer: t.string,
+  xAvatarURL2: t.union([t.string, t.undefined]),
+  xDisplayName: t.union([t.string, t.undefined]),
+});`),
+	)
+
+	It("should keep skipping untagged fields by default", func() {
+		result, err := generators.NewIoTsGenerator().Generate(Profile{})
+
+		Expect(err).To(BeNil())
+		Expect(result).NotTo(ContainSubstring("HTTPServer"))
+		Expect(result).To(ContainSubstring("DisplayName: t.union([t.string, t.undefined]),"))
+	})
+})
+
+var _ = Describe("IO-TS:Hoisted Anonymous Structs", func() {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Shipment struct {
+		Origin struct {
+			City string `json:"city"`
+			Zip  string `json:"zip"`
+		} `json:"origin"`
+		Destination struct {
+			City string `json:"city"`
+			Zip  string `json:"zip"`
+		} `json:"destination"`
+		Stops []struct {
+			Address Address `json:"address"`
+			Window  struct {
+				From string `json:"from"`
+			} `json:"window"`
+		} `json:"stops"`
+		Carrier *struct {
+			Name string `json:"name"`
+		} `json:"carrier" iots:"name=Carrier"`
+	}
+
+	It("should declare each distinct anonymous struct once", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{HoistAnonymousStructs: true})
+		result, err := generator.Generate(Shipment{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const ShipmentOriginC = t.type({
+  city: t.string,
+  zip: t.string,
+});
+export type ShipmentOrigin = t.TypeOf<typeof ShipmentOriginC>;
+
+export const AddressC = t.type({
+  city: t.string,
+});
+export type Address = t.TypeOf<typeof AddressC>;
+
+export const ShipmentStopsWindowC = t.type({
+  from: t.string,
+});
+export type ShipmentStopsWindow = t.TypeOf<typeof ShipmentStopsWindowC>;
+
+export const ShipmentStopsC = t.type({
+  address: AddressC,
+  window: ShipmentStopsWindowC,
+});
+export type ShipmentStops = t.TypeOf<typeof ShipmentStopsC>;
+
+export const CarrierC = t.type({
+  name: t.string,
+});
+export type Carrier = t.TypeOf<typeof CarrierC>;
+
+export const ShipmentC = t.type({
+  origin: ShipmentOriginC,
+  destination: ShipmentOriginC,
+  stops: t.array(ShipmentStopsC),
+  carrier: t.union([CarrierC, t.undefined]),
+});
+export type Shipment = t.TypeOf<typeof ShipmentC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should keep anonymous structs inline by default", func() {
+		result, err := generators.NewIoTsGenerator().Generate(Shipment{})
+
+		Expect(err).To(BeNil())
+		Expect(result).NotTo(ContainSubstring("ShipmentOriginC"))
+		Expect(strings.Count(result, "zip: t.string,")).To(Equal(2))
+	})
+})
+
+var _ = Describe("IO-TS:Embedded Structs as Intersections", func() {
+	type BaseEntity struct {
+		ID string `json:"id"`
+	}
+	type Timestamps struct {
+		CreatedAt string `json:"createdAt"`
+	}
+	type User struct {
+		BaseEntity
+		Timestamps `json:",inline"`
+		Name       string `json:"name"`
+	}
+	type Tag struct {
+		*BaseEntity
+	}
+	type Team struct {
+		BaseEntity `json:"base"`
+		Members    []User `json:"members"`
+		Tags       []Tag  `json:"tags"`
+		Settings   struct {
+			BaseEntity
+			Public bool `json:"public"`
+		} `json:"settings"`
+	}
+
+	It("should share the codec of embedded structs", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{EmbedAsIntersection: true})
+		result, err := generator.Generate(Team{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const BaseEntityC = t.type({
+  id: t.string,
+});
+export type BaseEntity = t.TypeOf<typeof BaseEntityC>;
+
+export const TimestampsC = t.type({
+  createdAt: t.string,
+});
+export type Timestamps = t.TypeOf<typeof TimestampsC>;
+
+export const UserC = t.intersection([BaseEntityC, TimestampsC, t.type({
+  name: t.string,
+})]);
+export type User = t.TypeOf<typeof UserC>;
+
+export const TagC = BaseEntityC;
+export type Tag = t.TypeOf<typeof TagC>;
+
+export const TeamC = t.type({
+  base: BaseEntityC,
+  members: t.array(UserC),
+  tags: t.array(TagC),
+  settings: t.intersection([BaseEntityC, t.type({
+  public: t.boolean,
+})]),
+});
+export type Team = t.TypeOf<typeof TeamC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should intersect embedded structs of recursive structs", func() {
+		type Node struct {
+			BaseEntity
+			Kids []Node `json:"kids"`
+		}
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{EmbedAsIntersection: true})
+		result, err := generator.Generate(Node{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const BaseEntityC = t.type({
+  id: t.string,
+});
+export type BaseEntity = t.TypeOf<typeof BaseEntityC>;
+
+export const NodeC = t.recursion(
+  'Node',
+  Self =>
+    t.intersection([BaseEntityC, t.type({
+      kids: t.array(Self),
+})]),
+);
+
+export type Node = t.TypeOf<typeof NodeC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should keep flattening embedded and inline fields by default", func() {
+		result, err := generators.NewIoTsGenerator().GenerateAll(User{}, Tag{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const UserC = t.type({
+  id: t.string,
+  createdAt: t.string,
+  name: t.string,
+});
+export type User = t.TypeOf<typeof UserC>;
+
+export const TagC = t.type({
+  id: t.string,
+});
+export type Tag = t.TypeOf<typeof TagC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should flatten embedded structs of recursive structs by default", func() {
+		type Node struct {
+			BaseEntity
+			Kids []Node `json:"kids"`
+		}
+		result, err := generators.NewIoTsGenerator().Generate(Node{})
+
+		Expect(err).To(BeNil())
+		Expect(result).NotTo(ContainSubstring("BaseEntityC"))
+		Expect(utils.NormalizeWhitespace(result)).To(ContainSubstring(utils.NormalizeWhitespace(`
+    t.type({
+      id: t.string,
+      kids: t.array(Self),
+    }),
+`)))
+	})
+})
+
+var _ = Describe("IO-TS:Readonly", func() {
+	type Line struct {
+		SKU string `json:"sku"`
+	}
+	type Cart struct {
+		Lines  []Line                 `json:"lines"`
+		Meta   map[string]interface{} `json:"meta"`
+		Coupon struct {
+			Code string `json:"code"`
+		} `json:"coupon"`
+		Draft []string `json:"draft" iots:"mutable"`
+	}
+	type Category struct {
+		Children []Category `json:"children"`
+	}
+
+	It("should emit deeply readonly codecs", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{Readonly: true})
+		result, err := generator.Generate(Cart{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const LineC = t.readonly(t.type({
+  sku: t.string,
+}));
+export type Line = t.TypeOf<typeof LineC>;
+
+export const CartC = t.readonly(t.type({
+  lines: t.readonlyArray(LineC),
+  meta: t.readonly(t.record(t.string, t.unknown)),
+  coupon: t.readonly(t.type({
+  code: t.string,
+})),
+  draft: t.array(t.string),
+}));
+export type Cart = t.TypeOf<typeof CartC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should make single fields readonly with the iots tag", func() {
+		type Order struct {
+			Lines []Line   `json:"lines" iots:"readonly"`
+			Main  Line     `json:"main" iots:"readonly"`
+			Tags  []string `json:"tags"`
+		}
+		result, err := generators.NewIoTsGenerator().Generate(Order{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("export const LineC = t.type({"))
+		Expect(result).To(ContainSubstring("lines: t.readonlyArray(t.readonly(LineC)),"))
+		Expect(result).To(ContainSubstring("main: t.readonly(LineC),"))
+		Expect(result).To(ContainSubstring("tags: t.array(t.string),"))
+	})
+
+	It("should keep the fields of named structs mutable behind a readonly field", func() {
+		type Shelf struct {
+			Items []string `json:"items"`
+		}
+		type Store struct {
+			Shelf Shelf `json:"shelf" iots:"readonly"`
+			Aisle struct {
+				Shelves []Shelf `json:"shelves"`
+			} `json:"aisle" iots:"readonly"`
+		}
+		result, err := generators.NewIoTsGenerator().Generate(Store{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const ShelfC = t.type({
+  items: t.array(t.string),
+});
+export type Shelf = t.TypeOf<typeof ShelfC>;
+
+export const StoreC = t.type({
+  shelf: t.readonly(ShelfC),
+  aisle: t.readonly(t.type({
+  shelves: t.readonlyArray(t.readonly(ShelfC)),
+})),
+});
+export type Store = t.TypeOf<typeof StoreC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should wrap references to hoisted structs of readonly fields", func() {
+		type RO struct {
+			Inner struct {
+				Value string `json:"value"`
+			} `json:"inner" iots:"readonly"`
+		}
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{HoistAnonymousStructs: true})
+		result, err := generator.Generate(RO{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("export const ROInnerC = t.type({"))
+		Expect(result).To(ContainSubstring("inner: t.readonly(ROInnerC),"))
+	})
+
+	It("should emit readonly recursive codecs", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{Readonly: true})
+		result, err := generator.Generate(Category{})
+
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(ContainSubstring(utils.NormalizeWhitespace(`
+export const CategoryC = t.recursion(
+  'Category',
+  Self =>
+    t.readonly(t.type({
+      children: t.readonlyArray(Self),
+    })),
+);`)))
+	})
+})
@@ -0,0 +1,91 @@
+package generators
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// structFrame records a named type being generated and the field path depth where it starts,
+// so anonymous structs below it can be named after it
+type structFrame struct {
+	name  string
+	depth int
+}
+
+// enterStruct starts naming anonymous structs after the given named type
+func (s *Session) enterStruct(t reflect.Type) {
+	s.structs = append(s.structs, structFrame{name: s.structName(t), depth: len(s.diagnostics.path)})
+}
+
+// exitStruct stops naming anonymous structs after the current named type
+func (s *Session) exitStruct() {
+	s.structs = s.structs[:len(s.structs)-1]
+}
+
+// hoistStruct declares an anonymous struct as a named codec and returns a reference to it.
+// Identical anonymous structs are the same reflect.Type, so each is declared once under the
+// name of its first occurrence.
+func (s *Session) hoistStruct(t reflect.Type) string {
+	name, ok := s.hoisted[t]
+	if !ok {
+		name = s.hoistName
+		if name == "" {
+			name = s.anonymousStructName()
+		}
+		s.hoisted[t] = name
+		key := "anonymous:" + name
+		if !isIdentifier(name) {
+			s.diagnostics.report(SeverityError, t, nil, "type name %q is not a valid TypeScript identifier", name)
+		}
+		s.declareNames(t, []string{name + "C"}, name)
+		s.hoistName = ""
+		restore := s.setReadonly(s.options.Readonly)
+		s.enterDeclaration(key)
+		code := fmt.Sprintf("export const %sC = %s;\n", name, s.generateInlineStruct(t))
+		code += fmt.Sprintf("export type %s = t.TypeOf<typeof %sC>;\n\n", name, name)
+		s.codeBuilder.AddDeclaration(Declaration{
+			Key:      key,
+			Name:     name,
+			Code:     code,
+			Requires: s.exitDeclaration(),
+		})
+		restore()
+	}
+	s.hoistName = ""
+	s.require("anonymous:" + name)
+	return name + "C"
+}
+
+// anonymousStructName derives a name from the enclosing named type and the field path below it,
+// e.g. OrderShippingAddress for Order.Shipping.Address. Elements directly below a named slice or
+// map are suffixed with Item or Value, e.g. PointsItem for type Points []struct{...}.
+func (s *Session) anonymousStructName() string {
+	var sb strings.Builder
+	var suffix string
+	depth := 0
+	if len(s.structs) > 0 {
+		frame := s.structs[len(s.structs)-1]
+		sb.WriteString(frame.name)
+		depth = frame.depth
+	}
+	fields := 0
+	for _, segment := range s.diagnostics.path[depth:] {
+		switch {
+		case strings.HasPrefix(segment, "["):
+			suffix += "Item"
+		case strings.HasPrefix(segment, "{"):
+			suffix += "Value"
+		default:
+			sb.WriteString(segment)
+			fields++
+		}
+	}
+	if fields == 0 && len(s.structs) > 0 {
+		sb.WriteString(suffix)
+	}
+	if sb.Len() == 0 {
+		return "Anonymous"
+	}
+	return sb.String()
+}
@@ -0,0 +1,119 @@
+package generators
+
+import (
+	"reflect"
+	"strings"
+)
+
+// iotsTag is the parsed form of an `iots` struct tag, which adjusts the generated codec of a
+// field without affecting encoding/json:
+//
+//	iots:"-"                                          excludes the field
+//	iots:"type=DateFromISOString,import=io-ts-types"  replaces the codec and imports its name
+//	iots:"type=t.array(UUID),import=io-ts-types:UUID" imports an explicit name
+//	iots:"optional" / iots:"required"                 overrides the optionality
+//	iots:"name=Address"                               names the anonymous struct hoisted from the field
+//	iots:"readonly" / iots:"mutable"                  overrides the Readonly option for the field
+type iotsTag struct {
+	skip     bool
+	codec    string
+	name     string
+	imports  []tagImport
+	optional bool
+	required bool
+	readonly bool
+	mutable  bool
+	// unknown lists the options that could not be parsed
+	unknown []string
+}
+
+// tagImport is a name imported from a module by an `iots` tag
+type tagImport struct {
+	module string
+	name   string
+}
+
+// parseIotsTag parses the `iots` tag of a field. Options are separated by commas outside brackets,
+// so type expressions may contain commas, e.g. type=t.union([A, B]).
+func parseIotsTag(field reflect.StructField) iotsTag {
+	tag := strings.TrimSpace(field.Tag.Get("iots"))
+	if tag == "-" {
+		return iotsTag{skip: true}
+	}
+	var parsed iotsTag
+	var modules []string
+	for _, option := range splitTagOptions(tag) {
+		key, value, hasValue := strings.Cut(strings.TrimSpace(option), "=")
+		switch {
+		case key == "":
+			continue
+		case key == "type" && hasValue:
+			parsed.codec = strings.TrimSpace(value)
+		case key == "name" && hasValue:
+			parsed.name = strings.TrimSpace(value)
+		case key == "import" && hasValue:
+			modules = append(modules, strings.TrimSpace(value))
+		case key == "optional" && !hasValue:
+			parsed.optional = true
+		case key == "required" && !hasValue:
+			parsed.required = true
+		case key == "readonly" && !hasValue:
+			parsed.readonly = true
+		case key == "mutable" && !hasValue:
+			parsed.mutable = true
+		default:
+			parsed.unknown = append(parsed.unknown, option)
+		}
+	}
+	for _, module := range modules {
+		module, name, explicit := strings.Cut(module, ":")
+		if !explicit {
+			name = leadingIdentifier(parsed.codec)
+		}
+		if name == "t" {
+			// io-ts itself is always imported
+			name = ""
+		}
+		if module == "" || name == "" {
+			parsed.unknown = append(parsed.unknown, "import="+module)
+			continue
+		}
+		parsed.imports = append(parsed.imports, tagImport{module: module, name: name})
+	}
+	return parsed
+}
+
+// splitTagOptions splits a tag on the commas that are not nested in brackets or quotes
+func splitTagOptions(tag string) []string {
+	var options []string
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range tag {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(' || r == '[' || r == '{' || r == '<':
+			depth++
+		case r == ')' || r == ']' || r == '}' || r == '>':
+			depth--
+		case r == ',' && depth == 0:
+			options = append(options, tag[start:i])
+			start = i + 1
+		}
+	}
+	return append(options, tag[start:])
+}
+
+// leadingIdentifier returns the identifier an expression starts with, e.g. DateFromISOString
+// for DateFromISOString or t for t.array(UUID)
+func leadingIdentifier(expression string) string {
+	end := 0
+	for end < len(expression) && isIdentifier(expression[:end+1]) {
+		end++
+	}
+	return expression[:end]
+}
@@ -0,0 +1,121 @@
+package generators
+
+// Ordering selects how declarations that do not depend on each other are ordered in the output.
+// Whatever the ordering, a codec is always declared before the codecs that use it.
+type Ordering int
+
+const (
+	// OrderDeclaration keeps the order in which types are discovered from the roots
+	OrderDeclaration Ordering = iota
+	// OrderAlphabetical sorts independent declarations by TypeScript name
+	OrderAlphabetical
+	// OrderByPackage groups declarations by Go package path, keeping discovery order within a package
+	OrderByPackage
+)
+
+// Declaration is a single top-level codec emitted by the generator
+type Declaration struct {
+	// Key identifies the Go type, e.g. "github.com/acme/api.Order"
+	Key     string
+	Name    string
+	PkgPath string
+	Code    string
+	// Requires lists the keys of the declarations referenced by Code
+	Requires []string
+}
+
+// orderDeclarations sorts declarations topologically, breaking ties with the given ordering.
+// Dependency cycles are reported as errors while generating, so the declarations always have an order.
+func orderDeclarations(declarations []Declaration, ordering Ordering) []Declaration {
+	less := func(i, j int) bool {
+		a, b := declarations[i], declarations[j]
+		switch ordering {
+		case OrderAlphabetical:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case OrderByPackage:
+			if a.PkgPath != b.PkgPath {
+				return a.PkgPath < b.PkgPath
+			}
+		}
+		return i < j
+	}
+
+	known := make(map[string]bool, len(declarations))
+	for _, d := range declarations {
+		if d.Key != "" {
+			known[d.Key] = true
+		}
+	}
+	emitted := make(map[string]bool, len(declarations))
+	done := make([]bool, len(declarations))
+	ready := func(d Declaration) bool {
+		for _, key := range d.Requires {
+			if known[key] && !emitted[key] && key != d.Key {
+				return false
+			}
+		}
+		return true
+	}
+
+	ordered := make([]Declaration, 0, len(declarations))
+	for len(ordered) < len(declarations) {
+		next := -1
+		for i, d := range declarations {
+			if !done[i] && ready(d) && (next == -1 || less(i, next)) {
+				next = i
+			}
+		}
+		if next == -1 {
+			panic("generators: dependency cycle between declarations")
+		}
+		done[next] = true
+		emitted[declarations[next].Key] = true
+		ordered = append(ordered, declarations[next])
+	}
+	return ordered
+}
+
+// dependencyFrame collects the declarations referenced while a declaration is being generated
+type dependencyFrame struct {
+	key      string
+	requires []string
+}
+
+// enterDeclaration starts collecting the dependencies of the declaration with the given key
+func (s *Session) enterDeclaration(key string) {
+	s.dependencies = append(s.dependencies, &dependencyFrame{key: key})
+}
+
+// exitDeclaration stops collecting and returns the dependencies of the current declaration
+func (s *Session) exitDeclaration() []string {
+	frame := s.dependencies[len(s.dependencies)-1]
+	s.dependencies = s.dependencies[:len(s.dependencies)-1]
+	return frame.requires
+}
+
+// require records that the declaration being generated references the given declaration.
+// Referencing a declaration that is still being generated is a cycle, which const declarations
+// cannot express, so it is reported as an error.
+func (s *Session) require(key string) {
+	if len(s.dependencies) == 0 {
+		return
+	}
+	frame := s.dependencies[len(s.dependencies)-1]
+	if frame.key == key {
+		return
+	}
+	for _, enclosing := range s.dependencies[:len(s.dependencies)-1] {
+		if enclosing.key == key {
+			s.diagnostics.report(SeverityError, nil, nil, "dependency cycle through %s is not supported", key)
+			return
+		}
+	}
+	for _, existing := range frame.requires {
+		if existing == key {
+			return
+		}
+	}
+	frame.requires = append(frame.requires, key)
+}
@@ -0,0 +1,112 @@
+package generators_test
+
+import (
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/fixtures"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/generators"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IO-TS:Ordering", func() {
+	type Zeta struct {
+		Value string `json:"value"`
+	}
+	type Beta struct {
+		Count int `json:"count"`
+	}
+	type Alpha struct {
+		Zeta Zeta `json:"zeta"`
+		Beta Beta `json:"beta"`
+	}
+
+	It("should sort independent declarations alphabetically while declaring dependencies first", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{Ordering: generators.OrderAlphabetical})
+		result, err := generator.Generate(Alpha{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const BetaC = t.type({
+  count: t.number,
+});
+export type Beta = t.TypeOf<typeof BetaC>;
+
+export const ZetaC = t.type({
+  value: t.string,
+});
+export type Zeta = t.TypeOf<typeof ZetaC>;
+
+export const AlphaC = t.type({
+  zeta: ZetaC,
+  beta: BetaC,
+});
+export type Alpha = t.TypeOf<typeof AlphaC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should keep discovery order by default", func() {
+		generator := generators.NewIoTsGenerator()
+		result, err := generator.GenerateAll(Beta{}, Alpha{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const BetaC = t.type({
+  count: t.number,
+});
+export type Beta = t.TypeOf<typeof BetaC>;
+
+export const ZetaC = t.type({
+  value: t.string,
+});
+export type Zeta = t.TypeOf<typeof ZetaC>;
+
+export const AlphaC = t.type({
+  zeta: ZetaC,
+  beta: BetaC,
+});
+export type Alpha = t.TypeOf<typeof AlphaC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should group declarations by package", func() {
+		type Holder struct {
+			Zeta Zeta               `json:"zeta"`
+			At   fixtures.AtExample `json:"at"`
+		}
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{Ordering: generators.OrderByPackage})
+		result, err := generator.Generate(Holder{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const AtExampleC = t.type({
+  '@atExample': t.string,
+});
+export type AtExample = t.TypeOf<typeof AtExampleC>;
+
+export const ZetaC = t.type({
+  value: t.string,
+});
+export type Zeta = t.TypeOf<typeof ZetaC>;
+
+export const HolderC = t.type({
+  zeta: ZetaC,
+  at: AtExampleC,
+});
+export type Holder = t.TypeOf<typeof HolderC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should report structs that reach each other instead of ordering them", func() {
+		_, err := generators.NewIoTsGenerator().Generate(fixtures.Author{})
+		Expect(err).To(MatchError("error: Author.Books[].Author (github.com/VictorMarcolino/golang-struct-to-io-ts/fixtures.Author) Author reaches itself through another type, which is not supported"))
+	})
+})
@@ -0,0 +1,134 @@
+package generators
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Enumer is implemented by enum types that list their own values, so they can be generated
+// even when packages.Load cannot see their declaration. EnumValues must return values of the
+// implementing type, or EnumConstant values to choose the constant names.
+type Enumer interface {
+	EnumValues() []interface{}
+}
+
+// RegisterEnum declares the values of an enum type in the shared package cache.
+// See PackageCache.RegisterEnum.
+func RegisterEnum(enumType interface{}, values ...interface{}) error {
+	return defaultPackageCache.RegisterEnum(enumType, values...)
+}
+
+// RegisterEnum declares the values of an enum type whose constants cannot be found by loading
+// its package: types in _test.go files, main packages, function bodies or modules outside the
+// working directory. enumType is a value of the type or its reflect.Type. Each value is either
+// a value of the type, whose constant name is derived from its String method or its value, or
+// an EnumConstant naming the value explicitly. Registered values take precedence over the source.
+func (c *PackageCache) RegisterEnum(enumType interface{}, values ...interface{}) error {
+	t, ok := enumType.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(enumType)
+	}
+	if t == nil || t.Name() == "" || t.PkgPath() == "" || !canHaveConstants(t.Kind()) {
+		return fmt.Errorf("cannot register %v as an enum: it must be a named string, numeric or boolean type", t)
+	}
+	constants, err := enumConstantsOf(t, values)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.registered[t] = constants
+	c.mu.Unlock()
+	return nil
+}
+
+// enumerConstants lists the values of a type implementing Enumer
+func enumerConstants(t reflect.Type) ([]EnumConstant, bool) {
+	enumer, ok := reflect.Zero(t).Interface().(Enumer)
+	if !ok {
+		if enumer, ok = reflect.New(t).Interface().(Enumer); !ok {
+			return nil, false
+		}
+	}
+	constants, err := enumConstantsOf(t, enumer.EnumValues())
+	if err != nil || len(constants) == 0 {
+		return nil, false
+	}
+	return constants, true
+}
+
+// enumConstantsOf converts values of an enum type into named constants
+func enumConstantsOf(t reflect.Type, values []interface{}) ([]EnumConstant, error) {
+	constants := make([]EnumConstant, 0, len(values))
+	names := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		name, doc := "", ""
+		if c, ok := value.(EnumConstant); ok {
+			name, doc, value = c.Name, c.Doc, c.Value
+		}
+		v := reflect.ValueOf(value)
+		if !v.IsValid() || !v.Type().ConvertibleTo(t) {
+			return nil, fmt.Errorf("cannot register %v as a value of %s", value, typePath(t))
+		}
+		v = v.Convert(t)
+		if name == "" {
+			name = enumValueName(t, v)
+		}
+		if _, exists := names[name]; exists {
+			return nil, fmt.Errorf("enum %s has more than one value named %s", typePath(t), name)
+		}
+		names[name] = struct{}{}
+		constants = append(constants, EnumConstant{Name: name, Value: reflectConstantValue(v), Doc: doc})
+	}
+	return constants, nil
+}
+
+// enumValueName derives a constant name from the String method of a value, or from the value itself.
+// Names that would not start with a letter are prefixed with the type name.
+func enumValueName(t reflect.Type, v reflect.Value) string {
+	label := fmt.Sprintf("%v", reflectConstantValue(v))
+	if stringer, ok := v.Interface().(fmt.Stringer); ok {
+		label = stringer.String()
+	}
+	var sb strings.Builder
+	upper := true
+	for _, r := range label {
+		if !(r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	name := sb.String()
+	if !isIdentifier(name) {
+		name = t.Name() + name
+	}
+	return name
+}
+
+// reflectConstantValue converts a value of a basic kind into the representation used for source constants
+func reflectConstantValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u > math.MaxInt64 {
+			return u
+		}
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
@@ -0,0 +1,108 @@
+package generators_test
+
+import (
+	"reflect"
+
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/generators"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type Priority int
+
+const (
+	PriorityLow Priority = iota + 1
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	if p == PriorityHigh {
+		return "high"
+	}
+	return "low"
+}
+
+func (Priority) EnumValues() []interface{} {
+	return []interface{}{PriorityLow, PriorityHigh}
+}
+
+var _ = Describe("IO-TS:Enum Registration", func() {
+	It("should generate enums registered for types declared inside functions", func() {
+		type Status string
+		type Ticket struct {
+			Status Status `json:"status"`
+		}
+		cache := generators.NewPackageCache()
+		Expect(cache.RegisterEnum(Status(""), Status("open"), Status("closed"))).To(Succeed())
+
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			PackageCache: cache,
+			Enums:        generators.EnumOptions{Order: generators.EnumOrderDeclaration},
+		})
+		result, diagnostics, err := generator.GenerateWithDiagnostics(Ticket{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const StatusOpen = "open" as const;
+export const StatusClosed = "closed" as const;
+
+export const StatusC = t.union([
+t.literal(StatusOpen),
+t.literal(StatusClosed)
+]);
+
+export type Status = t.TypeOf<typeof StatusC>;
+
+export const TicketC = t.type({
+  status: StatusC,
+});
+export type Ticket = t.TypeOf<typeof TicketC>;
+`
+		Expect(err).To(BeNil())
+		Expect(diagnostics).To(ConsistOf(unloadedPackage))
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should accept explicit constant names", func() {
+		type Level int
+		cache := generators.NewPackageCache()
+		Expect(cache.RegisterEnum(reflect.TypeOf(Level(0)),
+			generators.EnumConstant{Name: "Debug", Value: 10},
+			generators.EnumConstant{Name: "Info", Value: 20},
+		)).To(Succeed())
+
+		constants, err := cache.EnumConstants(reflect.TypeOf(Level(0)))
+		Expect(err).To(BeNil())
+		Expect(constants).To(Equal([]generators.EnumConstant{
+			{Name: "Debug", Value: int64(10)},
+			{Name: "Info", Value: int64(20)},
+		}))
+	})
+
+	It("should list the values of types implementing Enumer", func() {
+		type Task struct {
+			Priority Priority `json:"priority"`
+		}
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{PackageCache: generators.NewPackageCache()})
+		result, diagnostics, err := generator.GenerateWithDiagnostics(Task{})
+
+		Expect(err).To(BeNil())
+		Expect(diagnostics).To(ConsistOf(unloadedPackage))
+		Expect(utils.NormalizeWhitespace(result)).To(ContainSubstring(utils.NormalizeWhitespace(`
+export const PriorityHigh = 2 as const;
+export const PriorityLow = 1 as const;
+`)))
+	})
+
+	It("should reject types and values that cannot form an enum", func() {
+		type Point struct{ X int }
+		type Code string
+		cache := generators.NewPackageCache()
+		Expect(cache.RegisterEnum(Point{})).NotTo(Succeed())
+		Expect(cache.RegisterEnum("plain string", "a")).NotTo(Succeed())
+		Expect(cache.RegisterEnum(Code(""), 42.5)).NotTo(Succeed())
+		Expect(cache.RegisterEnum(Code(""), Code("a"), Code("A"))).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,158 @@
+package generators
+
+import (
+	"reflect"
+)
+
+// Session holds the state of a single generation run: the code built so far, the types
+// already declared and the diagnostics reported. Roots added to the same session share
+// their declarations; independent outputs use separate sessions or Reset.
+// A Session is not safe for concurrent use, but sessions of the same generator may run in parallel.
+type Session struct {
+	options       TypeScriptGeneratorOptions
+	typeConverter TypeConverter
+	codeBuilder   *CodeBuilder
+	diagnostics   *diagnostics
+	dependencies  []*dependencyFrame
+	declaredNames map[string]string
+	// hoisted names the anonymous structs declared as codecs by HoistAnonymousStructs
+	hoisted map[reflect.Type]string
+	// hoistName is the name chosen with the iots tag for the next hoisted struct
+	hoistName string
+	structs   []structFrame
+	// readonly is set while readonly codecs are emitted, see TypeScriptGeneratorOptions.Readonly
+	readonly bool
+	// names holds the names given to roots with AddNamed
+	names map[reflect.Type]string
+	// converting holds the named slices and maps being converted, to detect types containing themselves
+	converting map[reflect.Type]bool
+	// processing holds the keys of the structs being processed, to detect structs reaching themselves
+	// through other types
+	processing map[string]bool
+	// namedRoots holds the roots that are neither structs nor enums, e.g. type Users []User, so fields
+	// reference their codec instead of inlining it; declaring is the one whose codec is being built
+	namedRoots map[reflect.Type]bool
+	declaring  reflect.Type
+}
+
+// NewSession starts a new generation run using the generator's options
+func (g *IoTsGenerator) NewSession() *Session {
+	session := &Session{options: g.options}
+	session.Reset()
+	return session
+}
+
+// Reset discards everything generated so far so the session can produce an independent output
+func (s *Session) Reset() {
+	s.codeBuilder = NewCodeBuilder()
+	s.codeBuilder.ordering = s.options.Ordering
+	s.diagnostics = &diagnostics{}
+	s.dependencies = nil
+	s.declaredNames = make(map[string]string)
+	s.hoisted = make(map[reflect.Type]string)
+	s.hoistName = ""
+	s.structs = nil
+	s.readonly = s.options.Readonly
+	s.names = make(map[reflect.Type]string)
+	s.converting = make(map[reflect.Type]bool)
+	s.processing = make(map[string]bool)
+	s.namedRoots = make(map[reflect.Type]bool)
+	s.declaring = nil
+	s.typeConverter = &DefaultTypeConverter{session: s}
+}
+
+// Add generates the io-ts types of the given roots into the session.
+// Types already declared by earlier roots are not emitted again.
+func (s *Session) Add(roots ...interface{}) {
+	// Fields reference the codecs of named roots whichever of the roots comes first
+	for _, root := range roots {
+		s.registerNamedRoot(reflect.TypeOf(root))
+	}
+	for _, root := range roots {
+		s.addRoot(root)
+	}
+}
+
+// AddNamed generates the io-ts types of a root under the given name. The root may be an anonymous
+// struct; a named one is renamed wherever it is referenced in the session, so name it before
+// adding other roots that reference it.
+func (s *Session) AddNamed(name string, root interface{}) {
+	t := reflect.TypeOf(root)
+	if t == nil {
+		s.diagnostics.report(SeverityError, nil, nil, "input is nil")
+		return
+	}
+	t = dereferenceType(t)
+	if existing, ok := s.names[t]; ok && existing != name {
+		s.diagnostics.report(SeverityError, t, nil, "root is already named %s", existing)
+		return
+	}
+	if _, ok := s.names[t]; !ok && s.codeBuilder.IsTypeProcessed(getTypeKey(t)) {
+		s.diagnostics.report(SeverityError, t, nil, "cannot name the root %s: it was already declared as %s", name, s.structName(t))
+		return
+	}
+	s.names[t] = name
+	s.registerNamedRoot(t)
+	s.addRoot(root)
+}
+
+// Build assembles the module generated so far.
+// When errors were reported it returns a *GenerateError listing them.
+func (s *Session) Build() (string, error) {
+	if s.diagnostics.hasErrors() {
+		return "", &GenerateError{Diagnostics: s.Diagnostics()}
+	}
+	return s.codeBuilder.Build(), nil
+}
+
+// Diagnostics returns the warnings and errors reported since the session started or was last reset
+func (s *Session) Diagnostics() []Diagnostic {
+	return append([]Diagnostic(nil), s.diagnostics.items...)
+}
+
+// registerNamedRoot records a root that is a named type other than a struct or an enum
+func (s *Session) registerNamedRoot(t reflect.Type) {
+	if t == nil {
+		return
+	}
+	t = dereferenceType(t)
+	if t.Kind() == reflect.Struct || t.Name() == "" || t.PkgPath() == "" || s.options.PackageCache.IsEnumType(t) {
+		return
+	}
+	s.namedRoots[t] = true
+}
+
+// addRoot validates a root value and processes its type: a struct, an enum or any other named type
+func (s *Session) addRoot(root interface{}) {
+	t := reflect.TypeOf(root)
+	if t == nil {
+		s.diagnostics.report(SeverityError, nil, nil, "input is nil")
+		return
+	}
+	t = dereferenceType(t)
+
+	if _, named := s.names[t]; !named && t.Kind() != reflect.Struct && t.PkgPath() == "" {
+		// Predeclared and unnamed types such as string or []User need a name
+		s.diagnostics.report(SeverityError, t, nil, "input is not a struct")
+		return
+	}
+	if s.structName(t) == "" {
+		s.diagnostics.report(SeverityError, t, nil, "anonymous root type has no name; use AddNamed or GenerateNamed")
+		return
+	}
+
+	s.diagnostics.pushPath(s.structName(t))
+	defer s.diagnostics.popPath()
+	switch {
+	case t.Kind() == reflect.Struct:
+		s.processStruct(t)
+	case s.isEnumType(t):
+		if s.structName(t) != t.Name() {
+			s.diagnostics.report(SeverityError, t, nil, "enum roots cannot be renamed")
+			return
+		}
+		s.generateEnumType(t)
+	default:
+		s.processNamedType(t)
+	}
+}
@@ -0,0 +1,385 @@
+package generators_test
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/fixtures"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/generators"
+	"github.com/VictorMarcolino/golang-struct-to-io-ts/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IO-TS:Sessions", func() {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Customer struct {
+		Name    string  `json:"name"`
+		Address Address `json:"address"`
+	}
+	type Supplier struct {
+		Company string  `json:"company"`
+		Address Address `json:"address"`
+	}
+
+	It("should produce independent outputs when Generate is called twice", func() {
+		generator := generators.NewIoTsGenerator()
+		_, err := generator.Generate(Customer{})
+		Expect(err).To(BeNil())
+		result, err := generator.Generate(Supplier{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const AddressC = t.type({
+  city: t.string,
+});
+export type Address = t.TypeOf<typeof AddressC>;
+
+export const SupplierC = t.type({
+  company: t.string,
+  address: AddressC,
+});
+export type Supplier = t.TypeOf<typeof SupplierC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should emit a single deduplicated module for many roots", func() {
+		generator := generators.NewIoTsGenerator()
+		result, err := generator.GenerateAll(Customer{}, Supplier{}, &Customer{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const AddressC = t.type({
+  city: t.string,
+});
+export type Address = t.TypeOf<typeof AddressC>;
+
+export const CustomerC = t.type({
+  name: t.string,
+  address: AddressC,
+});
+export type Customer = t.TypeOf<typeof CustomerC>;
+
+export const SupplierC = t.type({
+  company: t.string,
+  address: AddressC,
+});
+export type Supplier = t.TypeOf<typeof SupplierC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should accumulate roots in a session until it is reset", func() {
+		session := generators.NewIoTsGenerator().NewSession()
+		session.Add(Customer{})
+		session.Add(Supplier{})
+		result, err := session.Build()
+		Expect(err).To(BeNil())
+		Expect(strings.Count(result, "export const AddressC")).To(Equal(1))
+		Expect(strings.Count(result, "import * as t from 'io-ts';")).To(Equal(1))
+
+		session.Reset()
+		session.Add(Supplier{})
+		result, err = session.Build()
+		Expect(err).To(BeNil())
+		Expect(result).NotTo(ContainSubstring("CustomerC"))
+		Expect(result).To(ContainSubstring("export const AddressC"))
+	})
+
+	It("should report invalid roots added to a session", func() {
+		session := generators.NewIoTsGenerator().NewSession()
+		session.Add(Customer{}, "not a struct")
+		_, err := session.Build()
+		Expect(err).To(MatchError("error: (string) input is not a struct"))
+	})
+})
+
+var _ = Describe("IO-TS:Concurrency", func() {
+	It("should share one configured generator and package cache across parallel runs", func() {
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{
+			PackageCache: generators.NewPackageCache(),
+		})
+		expected, err := generators.NewIoTsGenerator().GenerateAll(fixtures.Example{}, fixtures.RecursionExample{})
+		Expect(err).To(BeNil())
+
+		const runs = 16
+		results := make([]string, runs)
+		errs := make([]error, runs)
+		var wg sync.WaitGroup
+		for i := 0; i < runs; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], errs[i] = generator.GenerateAll(fixtures.Example{}, fixtures.RecursionExample{})
+			}(i)
+		}
+		wg.Wait()
+
+		for i := 0; i < runs; i++ {
+			Expect(errs[i]).To(BeNil())
+			Expect(results[i]).To(Equal(expected))
+		}
+	})
+
+	It("should not be affected by changes to the options after construction", func() {
+		type Event struct {
+			Payload interface{} `json:"payload"`
+		}
+		options := generators.TypeScriptGeneratorOptions{Strict: true, AllowUnknown: []string{"Event.Payload"}}
+		generator := generators.NewIoTsGenerator(options)
+		options.AllowUnknown[0] = "Event.Other"
+
+		_, err := generator.Generate(Event{})
+		Expect(err).To(BeNil())
+	})
+})
+
+var _ = Describe("Named Roots", func() {
+	type Customer struct {
+		Name string `json:"name"`
+	}
+	type Purchase struct {
+		Buyer Customer `json:"buyer"`
+	}
+
+	It("should generate anonymous root structs under the given name", func() {
+		result, err := generators.NewIoTsGenerator().GenerateNamed("Point", struct {
+			X int `json:"x"`
+			Y int `json:"y"`
+		}{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const PointC = t.type({
+  x: t.number,
+  y: t.number,
+});
+export type Point = t.TypeOf<typeof PointC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should rename a root wherever it is referenced", func() {
+		session := generators.NewIoTsGenerator().NewSession()
+		session.AddNamed("Client", Customer{})
+		session.Add(Purchase{})
+		result, err := session.Build()
+
+		expected := `
+import * as t from 'io-ts';
+
+export const ClientC = t.type({
+  name: t.string,
+});
+export type Client = t.TypeOf<typeof ClientC>;
+
+export const PurchaseC = t.type({
+  buyer: ClientC,
+});
+export type Purchase = t.TypeOf<typeof PurchaseC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should rename recursive roots", func() {
+		type Node struct {
+			Children []Node `json:"children"`
+		}
+		result, err := generators.NewIoTsGenerator().GenerateNamed("TreeNode", Node{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("export const TreeNodeC = t.recursion(\n  'TreeNode',"))
+		Expect(result).To(ContainSubstring("export type TreeNode = t.TypeOf<typeof TreeNodeC>;"))
+		Expect(result).NotTo(ContainSubstring("export const NodeC"))
+	})
+
+	It("should report anonymous roots without a name and roots named too late", func() {
+		_, err := generators.NewIoTsGenerator().Generate(struct {
+			X int `json:"x"`
+		}{})
+		Expect(err).To(MatchError(ContainSubstring("anonymous root type has no name")))
+
+		session := generators.NewIoTsGenerator().NewSession()
+		session.Add(Purchase{})
+		session.AddNamed("Client", Customer{})
+		_, err = session.Build()
+		Expect(err).To(MatchError(ContainSubstring("it was already declared as Customer")))
+
+		_, err = generators.NewIoTsGenerator().GenerateNamed("not-valid", Customer{})
+		Expect(err).To(MatchError(ContainSubstring(`type name "not-valid" is not a valid TypeScript identifier`)))
+	})
+})
+
+var _ = Describe("Non-struct Roots", func() {
+	type Entry struct {
+		Label string `json:"label"`
+	}
+	type Entries []Entry
+	type Lookup map[string]*Entry
+	type Email string
+
+	It("should declare a codec for named slices, maps and scalars", func() {
+		result, err := generators.NewIoTsGenerator().GenerateAll(Entries{}, Lookup{}, Email(""))
+
+		expected := `
+import * as t from 'io-ts';
+
+export const EntryC = t.type({
+  label: t.string,
+});
+export type Entry = t.TypeOf<typeof EntryC>;
+
+export const EntriesC = t.array(EntryC);
+export type Entries = t.TypeOf<typeof EntriesC>;
+
+export const LookupC = t.record(t.string, t.union([EntryC, t.undefined]));
+export type Lookup = t.TypeOf<typeof LookupC>;
+
+export const EmailC = t.string;
+export type Email = t.TypeOf<typeof EmailC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should reference the codecs of named roots from fields", func() {
+		type Catalog struct {
+			Entries Entries  `json:"entries"`
+			Owner   *Email   `json:"owner"`
+			Tags    []string `json:"tags"`
+		}
+		result, err := generators.NewIoTsGenerator().GenerateAll(Catalog{}, Entries{}, Email(""))
+
+		expected := `
+import * as t from 'io-ts';
+
+export const EntryC = t.type({
+  label: t.string,
+});
+export type Entry = t.TypeOf<typeof EntryC>;
+
+export const EntriesC = t.array(EntryC);
+export type Entries = t.TypeOf<typeof EntriesC>;
+
+export const EmailC = t.string;
+export type Email = t.TypeOf<typeof EmailC>;
+
+export const CatalogC = t.type({
+  entries: EntriesC,
+  owner: t.union([EmailC, t.undefined]),
+  tags: t.array(t.string),
+});
+export type Catalog = t.TypeOf<typeof CatalogC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+
+		result, err = generators.NewIoTsGenerator().Generate(Catalog{})
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("entries: t.array(EntryC),"))
+	})
+
+	It("should name hoisted structs after named slice and map roots", func() {
+		type Points []struct {
+			X int `json:"x"`
+		}
+		type Ranges map[string]struct {
+			From int `json:"from"`
+		}
+		generator := generators.NewIoTsGenerator(generators.TypeScriptGeneratorOptions{HoistAnonymousStructs: true})
+		result, err := generator.GenerateAll(Points{}, Ranges{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const PointsItemC = t.type({
+  x: t.number,
+});
+export type PointsItem = t.TypeOf<typeof PointsItemC>;
+
+export const PointsC = t.array(PointsItemC);
+export type Points = t.TypeOf<typeof PointsC>;
+
+export const RangesValueC = t.type({
+  from: t.number,
+});
+export type RangesValue = t.TypeOf<typeof RangesValueC>;
+
+export const RangesC = t.record(t.string, RangesValueC);
+export type Ranges = t.TypeOf<typeof RangesC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should export enums that no struct references", func() {
+		result, err := generators.NewIoTsGenerator().Generate(fixtures.Severity(0))
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("export const SeverityC = t.union(["))
+		Expect(result).To(ContainSubstring("export type Severity = t.TypeOf<typeof SeverityC>;"))
+	})
+
+	It("should name unnamed roots and decode string keyed map fields as records", func() {
+		type Index struct {
+			ByName map[string]Entry `json:"byName"`
+		}
+		result, err := generators.NewIoTsGenerator().GenerateNamed("Indexes", []Index{})
+
+		Expect(err).To(BeNil())
+		Expect(result).To(ContainSubstring("byName: t.record(t.string, EntryC),"))
+		Expect(result).To(ContainSubstring("export const IndexesC = t.array(IndexC);"))
+
+		_, err = generators.NewIoTsGenerator().Generate([]Index{})
+		Expect(err).To(MatchError("error: ([]generators_test.Index) input is not a struct"))
+	})
+
+	It("should emit Self for recursive map values", func() {
+		type Node struct {
+			Children map[string]*Node `json:"children"`
+			Index    map[string]Node  `json:"index"`
+		}
+		result, err := generators.NewIoTsGenerator().Generate(Node{})
+
+		expected := `
+import * as t from 'io-ts';
+
+export const NodeC = t.recursion(
+  'Node',
+  Self =>
+    t.type({
+      children: t.record(t.string, t.union([Self, t.undefined])),
+      index: t.record(t.string, Self),
+    }),
+);
+
+export type Node = t.TypeOf<typeof NodeC>;
+`
+		Expect(err).To(BeNil())
+		Expect(utils.NormalizeWhitespace(result)).To(Equal(utils.NormalizeWhitespace(expected)))
+	})
+
+	It("should report named maps and slices that contain themselves", func() {
+		type Tree map[string]Tree
+		type Chain []*Chain
+		type Forest struct {
+			Trees Tree `json:"trees"`
+		}
+		_, err := generators.NewIoTsGenerator().GenerateAll(Tree{}, Chain{})
+		Expect(err).To(MatchError(ContainSubstring("Tree{} (github.com/VictorMarcolino/golang-struct-to-io-ts/generators_test.Tree) recursive map types are not supported")))
+		Expect(err).To(MatchError(ContainSubstring("recursive slice types are not supported")))
+
+		_, err = generators.NewIoTsGenerator().Generate(Forest{})
+		Expect(err).To(MatchError(ContainSubstring("Forest.Trees{} (github.com/VictorMarcolino/golang-struct-to-io-ts/generators_test.Tree) recursive map types are not supported")))
+	})
+})